@@ -1,4 +1,4 @@
 #!/usr/bin/env bash
 p=github.com/ziutek/mymysql
 
-go $* $p/mysql $p/native $p/thrsafe $p/autorc $p/godrv
+go $* $p/mysql $p/mysql/geo $p/native $p/thrsafe $p/autorc $p/godrv
@@ -197,11 +197,57 @@ func (c *Conn) Prepare(sql string) (*Stmt, error) {
 	return &s, nil
 }
 
+// Automatic connect/reconnect/repeat version of PrepareCached. Returned
+// statement is owned by the cache of prepared statements, which is discarded
+// by reconnect, so use it immediately and don't keep it (use ExecCached if
+// the execution should be repeated after reconnect too).
+func (c *Conn) PrepareCached(sql string) (*Stmt, error) {
+	if err := c.connectIfNotConnected(); err != nil {
+		return nil, err
+	}
+	nn := 0
+	for {
+		raw, err := c.Raw.PrepareCached(sql)
+		if err == nil {
+			return &Stmt{raw, c}, nil
+		}
+		if c.reconnectIfNetErr(&nn, &err); err != nil {
+			return nil, err
+		}
+	}
+	panic(nil)
+}
+
+// Automatic connect/reconnect/repeat version of ExecCached. After reconnect
+// the statement is prepared again.
+func (c *Conn) ExecCached(sql string, params ...interface{}) (rows []mysql.Row, res mysql.Result, err error) {
+
+	if err = c.connectIfNotConnected(); err != nil {
+		return
+	}
+	nn := 0
+	for {
+		if rows, res, err = c.Raw.ExecCached(sql, params...); err == nil {
+			return
+		}
+		if c.reconnectIfNetErr(&nn, &err); err != nil {
+			return
+		}
+	}
+	panic(nil)
+}
+
 // Begin begins a transaction and calls f to complete it .
 // If f returns an error and IsNetErr(error) == true it reconnects and calls
 // f up to MaxRetries times. If error is of type *mysql.Error it tries rollback
 // the transaction.
 func (c *Conn) Begin(f func(mysql.Transaction, ...interface{}) error, args ...interface{}) error {
+	return c.BeginTx(mysql.TxOptions{}, f, args...)
+}
+
+// Like Begin but starts the transaction with options opts (see
+// mysql.TxOptions).
+func (c *Conn) BeginTx(opts mysql.TxOptions, f func(mysql.Transaction, ...interface{}) error, args ...interface{}) error {
 	err := c.connectIfNotConnected()
 	if err != nil {
 		return err
@@ -209,13 +255,13 @@ func (c *Conn) Begin(f func(mysql.Transaction, ...interface{}) error, args ...in
 	nn := 0
 	for {
 		var tr mysql.Transaction
-		if tr, err = c.Raw.Begin(); err == nil {
+		if tr, err = c.Raw.BeginTx(opts); err == nil {
 			if err = f(tr, args...); err == nil {
 				return nil
 			}
 		}
 		if c.reconnectIfNetErr(&nn, &err); err != nil {
-			if _, ok := err.(*mysql.Error); ok && tr.IsValid() {
+			if _, ok := err.(*mysql.Error); ok && tr != nil && tr.IsValid() {
 				tr.Rollback()
 			}
 			return err
@@ -73,6 +73,16 @@ func TestAutoConnectReconnect(t *testing.T) {
 	_, _, err = ins.Exec()
 	checkErr(t, err, nil)
 
+	// Insert using cached statement
+	_, _, err = c.ExecCached("insert R values (?, ?)", 3, "trzy")
+	checkErr(t, err, nil)
+
+	// Kill the connection (the cached statement is discarded by reconnect)
+	c.Query("kill %d", c.Raw.ThreadId())
+
+	_, _, err = c.ExecCached("insert R values (?, ?)", 4, "cztery")
+	checkErr(t, err, nil)
+
 	// Kill the connection
 	c.Query("kill %d", c.Raw.ThreadId())
 
@@ -81,9 +91,10 @@ func TestAutoConnectReconnect(t *testing.T) {
 	checkErr(t, err, nil)
 	id := res.Map("id")
 	name := res.Map("name")
-	if len(rows) != 2 ||
+	if len(rows) != 4 ||
 		rows[0].Int(id) != 1 || rows[0].Str(name) != "jeden" ||
-		rows[1].Int(id) != 2 || rows[1].Str(name) != "dwa" {
+		rows[1].Int(id) != 2 || rows[1].Str(name) != "dwa" ||
+		rows[3].Int(id) != 4 || rows[3].Str(name) != "cztery" {
 		t.Fatal("Bad result")
 	}
 
@@ -2,6 +2,7 @@
 package godrv
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
@@ -11,6 +12,7 @@ import (
 	"io"
 	"math"
 	"net"
+	"net/url"
 	"reflect"
 	"strings"
 	"time"
@@ -18,7 +20,8 @@ import (
 )
 
 type conn struct {
-	my mysql.Conn
+	my    mysql.Conn
+	reset bool // Reset session before reuse
 }
 
 func errFilter(err error) error {
@@ -42,6 +45,26 @@ func (c conn) Prepare(query string) (driver.Stmt, error) {
 	return stmt{st}, nil
 }
 
+// Executes query using statement from the cache of prepared statements (see
+// native.Conn.PrepareCached), so there is no prepare/close round trip if the
+// query was executed recently (implements driver.Execer).
+func (c conn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	st, err := c.my.PrepareCached(query)
+	if err != nil {
+		return nil, errFilter(err)
+	}
+	return stmt{st}.run(args)
+}
+
+// Like Exec (implements driver.Queryer).
+func (c conn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	st, err := c.my.PrepareCached(query)
+	if err != nil {
+		return nil, errFilter(err)
+	}
+	return stmt{st}.run(args)
+}
+
 func (c conn) Close() error {
 	err := c.my.Close()
 	c.my = nil
@@ -56,6 +79,52 @@ func (c conn) Begin() (driver.Tx, error) {
 	return tx{t}, nil
 }
 
+// Isolation levels of database/sql mapped to MySQL transaction options
+var txIsolation = map[sql.IsolationLevel]mysql.TxOptions{
+	sql.LevelDefault:         {},
+	sql.LevelReadUncommitted: {Isolation: mysql.ISOLATION_READ_UNCOMMITTED},
+	sql.LevelReadCommitted:   {Isolation: mysql.ISOLATION_READ_COMMITTED},
+	sql.LevelRepeatableRead:  {Isolation: mysql.ISOLATION_REPEATABLE_READ},
+	sql.LevelSnapshot: {
+		Isolation:          mysql.ISOLATION_REPEATABLE_READ,
+		ConsistentSnapshot: true,
+	},
+	sql.LevelSerializable: {Isolation: mysql.ISOLATION_SERIALIZABLE},
+}
+
+// Starts transaction with isolation level and read-only mode specified in
+// opts (implements driver.ConnBeginTx). sql.LevelSnapshot is REPEATABLE READ
+// WITH CONSISTENT SNAPSHOT.
+func (c conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	o, ok := txIsolation[sql.IsolationLevel(opts.Isolation)]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported isolation level: %s",
+			sql.IsolationLevel(opts.Isolation))
+	}
+	o.ReadOnly = opts.ReadOnly
+	t, err := c.my.BeginTx(o)
+	if err != nil {
+		return nil, errFilter(err)
+	}
+	return tx{t}, nil
+}
+
+// Resets session state before the connection is reused by database/sql
+// (implements driver.SessionResetter). Does nothing if session reset isn't
+// enabled (see SetResetSession).
+func (c conn) ResetSession(ctx context.Context) error {
+	if !c.reset {
+		return nil
+	}
+	if err := c.my.ResetSession(); err != nil {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
 type tx struct {
 	my mysql.Transaction
 }
@@ -82,7 +151,26 @@ func (s stmt) NumInput() int {
 	return s.my.NumParam()
 }
 
+// database/sql uses time.Time zero for MySQL zero datetime, so it is
+// converted to mysql.ZeroTime before binding (and back by Next).
+func zeroTimeArgs(args []driver.Value) {
+	for i, a := range args {
+		if t, ok := a.(time.Time); ok && t.IsZero() {
+			args[i] = mysql.ZeroTime
+		}
+	}
+}
+
+// Converts mysql.ZeroTime to time.Time zero.
+func driverTime(t time.Time) time.Time {
+	if mysql.IsZeroTime(t) {
+		return time.Time{}
+	}
+	return t
+}
+
 func (s stmt) run(args []driver.Value) (*rowsRes, error) {
+	zeroTimeArgs(args)
 	a := (*[]interface{})(unsafe.Pointer(&args))
 	res, err := s.my.Run(*a...)
 	if err != nil {
@@ -131,7 +219,8 @@ func (r rowsRes) Close() error {
 	return nil
 }
 
-// DATE, DATETIME, TIMESTAMP are treated as they are in Local time zone
+// DATE, DATETIME, TIMESTAMP are treated as they are in Local time zone. MySQL
+// zero date/datetime is returned as time.Time zero.
 func (r rowsRes) Next(dest []driver.Value) error {
 	err := r.my.ScanRow(r.row)
 	if err != nil {
@@ -144,13 +233,13 @@ func (r rowsRes) Next(dest []driver.Value) error {
 		}
 		switch c := col.(type) {
 		case time.Time:
-			dest[i] = c
+			dest[i] = driverTime(c)
 			continue
 		case mysql.Timestamp:
-			dest[i] = c.Time
+			dest[i] = driverTime(c.Time)
 			continue
 		case mysql.Date:
-			dest[i] = c.Localtime()
+			dest[i] = driverTime(c.Localtime())
 			continue
 		}
 		v := reflect.ValueOf(col)
@@ -181,9 +270,24 @@ func (r rowsRes) Next(dest []driver.Value) error {
 
 type Driver struct {
 	// Defaults
-	proto, laddr, raddr, user, passwd, db string
+	proto, laddr, raddr, user, passwd, db, charset string
+
+	initCmds      []string
+	resetSession  bool
+	stmtCacheSize int
+}
 
-	initCmds []string
+// Parses NAME=VALUE connection attribute from URI.
+func parseAttr(a string) (name, value string, err error) {
+	nv := strings.SplitN(a, "=", 2)
+	if len(nv) != 2 {
+		return "", "", errors.New("Wrong connection attribute in URI")
+	}
+	if name, err = url.PathUnescape(nv[0]); err != nil {
+		return
+	}
+	value, err = url.PathUnescape(nv[1])
+	return
 }
 
 // Open new connection. The uri need to have the following syntax:
@@ -195,16 +299,27 @@ type Driver struct {
 //   DBNAME/USER/PASSWD
 //   unix:SOCKPATH*DBNAME/USER/PASSWD
 //   tcp:ADDR*DBNAME/USER/PASSWD
+//
+// Protocol specific part may be followed by connection attributes:
+//   tcp:ADDR,NAME=VALUE,NAME=VALUE*DBNAME/USER/PASSWD
+//   ,NAME=VALUE*DBNAME/USER/PASSWD
+// NAME and VALUE may contain %XX escapes (like in URL path), so ',', '*' and
+// '%' can be passed as %2C, %2A and %25.
 func (d *Driver) Open(uri string) (driver.Conn, error) {
 	pd := strings.SplitN(uri, "*", 2)
+	var attrs []string
 	if len(pd) == 2 {
 		// Parse protocol part of URI
-		p := strings.SplitN(pd[0], ":", 2)
-		if len(p) != 2 {
-			return nil, errors.New("Wrong protocol part of URI")
+		attrs = strings.Split(pd[0], ",")
+		if attrs[0] != "" {
+			p := strings.SplitN(attrs[0], ":", 2)
+			if len(p) != 2 {
+				return nil, errors.New("Wrong protocol part of URI")
+			}
+			d.proto = p[0]
+			d.raddr = p[1]
 		}
-		d.proto = p[0]
-		d.raddr = p[1]
+		attrs = attrs[1:]
 		// Remove protocol part
 		pd = pd[1:]
 	}
@@ -218,7 +333,21 @@ func (d *Driver) Open(uri string) (driver.Conn, error) {
 	d.passwd = dup[2]
 
 	// Establish the connection
-	c := conn{mysql.New(d.proto, d.laddr, d.raddr, d.user, d.passwd, d.db)}
+	c := conn{
+		my:    mysql.New(d.proto, d.laddr, d.raddr, d.user, d.passwd, d.db),
+		reset: d.resetSession,
+	}
+	if err := c.my.SetCharset(d.charset); err != nil {
+		return nil, err
+	}
+	for _, a := range attrs {
+		name, value, err := parseAttr(a)
+		if err != nil {
+			return nil, err
+		}
+		c.my.SetAttr(name, value)
+	}
+	c.my.SetStmtCacheSize(d.stmtCacheSize)
 	for _, q := range d.initCmds {
 		c.my.Register(q) // Register initialisation commands
 	}
@@ -229,7 +358,7 @@ func (d *Driver) Open(uri string) (driver.Conn, error) {
 }
 
 // Driver automatically registered in database/sql
-var d = Driver{proto: "tcp", raddr: "127.0.0.1:3306"}
+var d = Driver{proto: "tcp", raddr: "127.0.0.1:3306", charset: "utf8"}
 
 // Registers initialisation commands.
 // This is workaround, see http://codereview.appspot.com/5706047
@@ -237,7 +366,25 @@ func Register(query string) {
 	d.initCmds = append(d.initCmds, query)
 }
 
+// Sets character set or collation (default utf8) that is negotiated with the
+// server during connect.
+func SetCharset(name string) {
+	d.charset = name
+}
+
+// Enables/disables session reset (see native.Conn.ResetSession) every time
+// database/sql reuses a connection from its pool. It requires at least one
+// additional round trip to the server for every reuse.
+func SetResetSession(on bool) {
+	d.resetSession = on
+}
+
+// If size > 0 sets maximum number of prepared statements cached by every
+// connection for Exec and Query (see native.Conn.SetStmtCacheSize).
+func SetStmtCacheSize(size int) {
+	d.stmtCacheSize = size
+}
+
 func init() {
-	Register("SET NAMES utf8")
 	sql.Register("mymysql", &d)
 }
@@ -2,7 +2,10 @@ package godrv
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"github.com/ziutek/mymysql/mysql"
 	"testing"
+	"time"
 )
 
 func init() {
@@ -134,3 +137,31 @@ func TestMediumInt(t *testing.T) {
 		t.Fatalf("%d rows read, %d expected", i, n)
 	}
 }
+
+func TestParseAttr(t *testing.T) {
+	name, value, err := parseAttr("app%2Aname=a%2Cb%2A%25=c")
+	if err != nil || name != "app*name" || value != "a,b*%=c" {
+		t.Fatalf("parseAttr: %q %q %v", name, value, err)
+	}
+	if _, _, err = parseAttr("noval"); err == nil {
+		t.Fatal("no error for attribute without value")
+	}
+	if _, _, err = parseAttr("a=%zz"); err == nil {
+		t.Fatal("no error for bad escape")
+	}
+}
+
+func TestZeroTime(t *testing.T) {
+	tt := time.Date(2014, 3, 4, 5, 6, 7, 0, time.Local)
+	args := []driver.Value{time.Time{}, tt, int64(1)}
+	zeroTimeArgs(args)
+	if args[0] != mysql.ZeroTime || args[1] != tt || args[2] != int64(1) {
+		t.Fatalf("bad args: %v", args)
+	}
+	if r := driverTime(mysql.ZeroTime); !r.IsZero() {
+		t.Fatalf("MySQL zero returned as %v", r)
+	}
+	if r := driverTime(tt); !r.Equal(tt) {
+		t.Fatalf("bad time: %v", r)
+	}
+}
@@ -0,0 +1,258 @@
+package mysql
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error returned by the server for a batch of rows inserted by BulkInserter
+type BulkError struct {
+	First int // Number of the first row in the batch (counting from 0)
+	Count int // Number of rows in the batch
+	Err   error
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("rows %d-%d: %s", e.First, e.First+e.Count-1, e.Err)
+}
+
+// BulkInserter inserts rows into a table using multi-row INSERT statements.
+// Rows are buffered and sent in statements that don't exceed the maximum
+// packet size accepted by the server (max_allowed_packet).
+type BulkInserter struct {
+	Ignore      bool   // Use INSERT IGNORE
+	Replace     bool   // Use REPLACE instead of INSERT
+	OnDuplicate string // Assignments for ON DUPLICATE KEY UPDATE clause
+
+	// Maximum size of the statement. If 0, @@max_allowed_packet is read
+	// from the server before the first statement.
+	MaxSize int
+
+	conn    ConnCommon
+	table   string
+	columns []string
+
+	buf      []byte // Statement that is built
+	n        int    // Number of rows in buf
+	first    int    // Number of the first row in buf
+	affected uint64
+	errors   []*BulkError
+}
+
+// Returns BulkInserter that inserts rows into columns of table using c.
+func NewBulkInserter(c ConnCommon, table string, columns ...string) *BulkInserter {
+	return &BulkInserter{conn: c, table: table, columns: columns}
+}
+
+// Quotes identifier with backticks. Identifier of the form db.name is
+// quoted as `db`.`name`.
+func quoteIdent(name string) string {
+	parts := strings.Split(name, ".")
+	for ii, p := range parts {
+		parts[ii] = "`" + strings.Replace(p, "`", "``", -1) + "`"
+	}
+	return strings.Join(parts, ".")
+}
+
+func (bi *BulkInserter) header() string {
+	verb := "INSERT "
+	if bi.Replace {
+		verb = "REPLACE "
+	} else if bi.Ignore {
+		verb = "INSERT IGNORE "
+	}
+	cols := make([]string, len(bi.columns))
+	for ii, c := range bi.columns {
+		cols[ii] = quoteIdent(c)
+	}
+	return verb + "INTO " + quoteIdent(bi.table) +
+		" (" + strings.Join(cols, ",") + ") VALUES "
+}
+
+func (bi *BulkInserter) trailer() string {
+	if bi.OnDuplicate == "" {
+		return ""
+	}
+	return " ON DUPLICATE KEY UPDATE " + bi.OnDuplicate
+}
+
+// Appends SQL literal that represents v to buf.
+func (bi *BulkInserter) appendValue(buf []byte, v reflect.Value) ([]byte, error) {
+	if !v.IsValid() {
+		return append(buf, "NULL"...), nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return append(buf, "NULL"...), nil
+		}
+		v = v.Elem()
+	}
+	if v.CanInterface() {
+		switch x := v.Interface().(type) {
+		case time.Time:
+			return append(append(append(buf, '\''), TimeString(x)...), '\''), nil
+		case Timestamp:
+			return append(append(append(buf, '\''), x.String()...), '\''), nil
+		case Date:
+			return append(append(append(buf, '\''), x.String()...), '\''), nil
+		case time.Duration:
+			return append(append(append(buf, '\''), DurationString(x)...), '\''), nil
+		}
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(buf, v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return strconv.AppendUint(buf, v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return buf, ErrBindUnkType
+		}
+		return strconv.AppendFloat(buf, f, 'g', -1, v.Type().Bits()), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return append(buf, '1'), nil
+		}
+		return append(buf, '0'), nil
+	case reflect.String:
+		buf = append(buf, '\'')
+		buf = append(buf, bi.conn.EscapeString(v.String())...)
+		return append(buf, '\''), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.IsNil() {
+				return append(buf, "NULL"...), nil
+			}
+			// Binary data as hexadecimal literal
+			b := v.Bytes()
+			buf = append(buf, "X'"...)
+			n := len(buf)
+			buf = append(buf, make([]byte, hex.EncodedLen(len(b)))...)
+			hex.Encode(buf[n:], b)
+			return append(buf, '\''), nil
+		}
+	}
+	return buf, ErrBindUnkType
+}
+
+func isTimeType(typ reflect.Type) bool {
+	return typ == reflect.TypeOf(time.Time{}) ||
+		typ == reflect.TypeOf(Timestamp{}) || typ == reflect.TypeOf(Date{})
+}
+
+// Returns values of row as "(v1,v2,...)"
+func (bi *BulkInserter) row(values []interface{}) ([]byte, error) {
+	var vals []reflect.Value
+	if len(values) == 1 {
+		v := reflect.Indirect(reflect.ValueOf(values[0]))
+		if v.Kind() == reflect.Struct && !isTimeType(v.Type()) {
+			// Exported fields of the struct
+			for ii := 0; ii < v.NumField(); ii++ {
+				if v.Type().Field(ii).PkgPath == "" {
+					vals = append(vals, v.Field(ii))
+				}
+			}
+		}
+	}
+	if vals == nil {
+		for _, val := range values {
+			vals = append(vals, reflect.ValueOf(val))
+		}
+	}
+	if len(vals) != len(bi.columns) {
+		return nil, ErrBindCount
+	}
+	buf := []byte{'('}
+	for ii, v := range vals {
+		if ii > 0 {
+			buf = append(buf, ',')
+		}
+		var err error
+		if buf, err = bi.appendValue(buf, v); err != nil {
+			return nil, err
+		}
+	}
+	return append(buf, ')'), nil
+}
+
+// Adds row to the statement. A row can be specified as a list of values or
+// a struct (or pointer to struct) whose exported fields are the values.
+// Values may be of the following types: intXX, uintXX, floatXX, bool,
+// string, []byte, time.Time, Timestamp, Date, time.Duration, pointers to them
+// (nil is NULL) and nil. If the statement would exceed MaxSize, buffered rows
+// are inserted first (see Flush).
+func (bi *BulkInserter) Add(values ...interface{}) error {
+	row, err := bi.row(values)
+	if err != nil {
+		return err
+	}
+	if bi.MaxSize == 0 {
+		r, _, err := bi.conn.QueryFirst("SELECT @@max_allowed_packet")
+		if err != nil {
+			return err
+		}
+		bi.MaxSize = r.Int(0)
+	}
+	// Size of the statement in COM_QUERY packet after adding the row
+	size := 1 + len(bi.buf) + 1 + len(row) + len(bi.trailer())
+	if bi.n == 0 {
+		size = 1 + len(bi.header()) + len(row) + len(bi.trailer())
+	}
+	if size > bi.MaxSize {
+		if bi.n == 0 {
+			return ErrPktLong
+		}
+		if err = bi.Flush(); err != nil {
+			return err
+		}
+	}
+	if bi.n == 0 {
+		bi.buf = append(bi.buf[:0], bi.header()...)
+	} else {
+		bi.buf = append(bi.buf, ',')
+	}
+	bi.buf = append(bi.buf, row...)
+	bi.n++
+	return nil
+}
+
+// Inserts buffered rows. Errors returned by the server are recorded (see
+// Errors) and don't stop inserting of subsequent rows. Other errors (eg.
+// network errors) are returned.
+func (bi *BulkInserter) Flush() error {
+	if bi.n == 0 {
+		return nil
+	}
+	bi.buf = append(bi.buf, bi.trailer()...)
+	_, res, err := bi.conn.Query(string(bi.buf))
+	first, n := bi.first, bi.n
+	bi.first += bi.n
+	bi.n = 0
+	if err != nil {
+		if _, ok := err.(*Error); !ok {
+			return err
+		}
+		bi.errors = append(bi.errors, &BulkError{First: first, Count: n, Err: err})
+		return nil
+	}
+	bi.affected += res.AffectedRows()
+	return nil
+}
+
+// Returns sum of affected rows reported by the server for all inserted
+// batches of rows.
+func (bi *BulkInserter) AffectedRows() uint64 {
+	return bi.affected
+}
+
+// Returns errors returned by the server for batches of rows.
+func (bi *BulkInserter) Errors() []*BulkError {
+	return bi.errors
+}
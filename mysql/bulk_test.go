@@ -0,0 +1,111 @@
+package mysql
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type bulkResult struct {
+	Result
+	affected uint64
+}
+
+func (r bulkResult) AffectedRows() uint64 {
+	return r.affected
+}
+
+// Connection that records queries and returns error for queries that contain
+// "bad"
+type bulkConn struct {
+	ConnCommon
+	queries []string
+}
+
+func (c *bulkConn) EscapeString(s string) string {
+	return strings.Replace(s, "'", "\\'", -1)
+}
+
+func (c *bulkConn) Query(sql string, params ...interface{}) ([]Row, Result, error) {
+	c.queries = append(c.queries, sql)
+	if strings.Contains(sql, "bad") {
+		return nil, nil, &Error{Code: ER_DUP_ENTRY, Msg: []byte("Duplicate")}
+	}
+	return nil, bulkResult{affected: uint64(strings.Count(sql, "),(") + 1)}, nil
+}
+
+func (c *bulkConn) QueryFirst(sql string, params ...interface{}) (Row, Result, error) {
+	c.queries = append(c.queries, sql)
+	return Row{int64(80)}, nil, nil
+}
+
+type bulkRow struct {
+	Id   int
+	Name *string
+	note string
+}
+
+func TestBulkInserter(t *testing.T) {
+	c := new(bulkConn)
+	bi := NewBulkInserter(c, "db.t", "id", "name")
+	bi.Ignore = true
+	name := "O'Neil"
+	rows := [][]interface{}{
+		{1, "a"},
+		{2, []byte{0, 0xff}},
+		{bulkRow{Id: 3, Name: &name}},
+		{&bulkRow{Id: 4}},
+		{5, "bad"},
+		{6, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+	for _, row := range rows {
+		if err := bi.Add(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bi.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{
+		"SELECT @@max_allowed_packet",
+		"INSERT IGNORE INTO `db`.`t` (`id`,`name`) VALUES (1,'a'),(2,X'00ff')",
+		"INSERT IGNORE INTO `db`.`t` (`id`,`name`) VALUES (3,'O\\'Neil'),(4,NULL)",
+		"INSERT IGNORE INTO `db`.`t` (`id`,`name`) VALUES (5,'bad')",
+		"INSERT IGNORE INTO `db`.`t` (`id`,`name`) VALUES " +
+			"(6,'2020-01-02 03:04:05')",
+	}
+	if len(c.queries) != len(exp) {
+		t.Fatalf("queries: %q", c.queries)
+	}
+	for ii, q := range exp {
+		if c.queries[ii] != q {
+			t.Errorf("query %d: %q exp: %q", ii, c.queries[ii], q)
+		}
+	}
+	if bi.AffectedRows() != 5 {
+		t.Errorf("bad affected rows: %d", bi.AffectedRows())
+	}
+	errs := bi.Errors()
+	if len(errs) != 1 || errs[0].First != 4 || errs[0].Count != 1 {
+		t.Errorf("bad errors: %v", errs)
+	}
+
+	if err := bi.Add(1); err != ErrBindCount {
+		t.Errorf("bad error for wrong number of values: %v", err)
+	}
+	if err := bi.Add(1, strings.Repeat("x", 100)); err != ErrPktLong {
+		t.Errorf("bad error for too long row: %v", err)
+	}
+
+	c.queries = nil
+	bi = NewBulkInserter(c, "t", "a")
+	bi.MaxSize = 1000
+	bi.OnDuplicate = "a=VALUES(a)"
+	bi.Add(1.5)
+	bi.Add(true)
+	bi.Flush()
+	if len(c.queries) != 1 || c.queries[0] != "INSERT INTO `t` (`a`) "+
+		"VALUES (1.5),(1) ON DUPLICATE KEY UPDATE a=VALUES(a)" {
+		t.Fatalf("queries: %q", c.queries)
+	}
+}
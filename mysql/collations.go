@@ -0,0 +1,184 @@
+package mysql
+
+import "strings"
+
+// Id of the binary collation (character set of binary strings and numbers)
+const BINARY_COLLATION = 63
+
+type collation struct {
+	name, charset string
+}
+
+// MySQL collations by id (see SHOW COLLATION)
+var collations = map[uint16]collation{
+	1:  {"big5_chinese_ci", "big5"},
+	2:  {"latin2_czech_cs", "latin2"},
+	3:  {"dec8_swedish_ci", "dec8"},
+	4:  {"cp850_general_ci", "cp850"},
+	5:  {"latin1_german1_ci", "latin1"},
+	6:  {"hp8_english_ci", "hp8"},
+	7:  {"koi8r_general_ci", "koi8r"},
+	8:  {"latin1_swedish_ci", "latin1"},
+	9:  {"latin2_general_ci", "latin2"},
+	10: {"swe7_swedish_ci", "swe7"},
+	11: {"ascii_general_ci", "ascii"},
+	12: {"ujis_japanese_ci", "ujis"},
+	13: {"sjis_japanese_ci", "sjis"},
+	14: {"cp1251_bulgarian_ci", "cp1251"},
+	15: {"latin1_danish_ci", "latin1"},
+	16: {"hebrew_general_ci", "hebrew"},
+	18: {"tis620_thai_ci", "tis620"},
+	19: {"euckr_korean_ci", "euckr"},
+	20: {"latin7_estonian_cs", "latin7"},
+	21: {"latin2_hungarian_ci", "latin2"},
+	22: {"koi8u_general_ci", "koi8u"},
+	23: {"cp1251_ukrainian_ci", "cp1251"},
+	24: {"gb2312_chinese_ci", "gb2312"},
+	25: {"greek_general_ci", "greek"},
+	26: {"cp1250_general_ci", "cp1250"},
+	27: {"latin2_croatian_ci", "latin2"},
+	28: {"gbk_chinese_ci", "gbk"},
+	29: {"cp1257_lithuanian_ci", "cp1257"},
+	30: {"latin5_turkish_ci", "latin5"},
+	31: {"latin1_german2_ci", "latin1"},
+	32: {"armscii8_general_ci", "armscii8"},
+	33: {"utf8_general_ci", "utf8"},
+	34: {"cp1250_czech_cs", "cp1250"},
+	35: {"ucs2_general_ci", "ucs2"},
+	36: {"cp866_general_ci", "cp866"},
+	37: {"keybcs2_general_ci", "keybcs2"},
+	38: {"macce_general_ci", "macce"},
+	39: {"macroman_general_ci", "macroman"},
+	40: {"cp852_general_ci", "cp852"},
+	41: {"latin7_general_ci", "latin7"},
+	42: {"latin7_general_cs", "latin7"},
+	43: {"macce_bin", "macce"},
+	44: {"cp1250_croatian_ci", "cp1250"},
+	45: {"utf8mb4_general_ci", "utf8mb4"},
+	46: {"utf8mb4_bin", "utf8mb4"},
+	47: {"latin1_bin", "latin1"},
+	48: {"latin1_general_ci", "latin1"},
+	49: {"latin1_general_cs", "latin1"},
+	50: {"cp1251_bin", "cp1251"},
+	51: {"cp1251_general_ci", "cp1251"},
+	52: {"cp1251_general_cs", "cp1251"},
+	53: {"macroman_bin", "macroman"},
+	54: {"utf16_general_ci", "utf16"},
+	55: {"utf16_bin", "utf16"},
+	56: {"utf16le_general_ci", "utf16le"},
+	57: {"cp1256_general_ci", "cp1256"},
+	58: {"cp1257_bin", "cp1257"},
+	59: {"cp1257_general_ci", "cp1257"},
+	60: {"utf32_general_ci", "utf32"},
+	61: {"utf32_bin", "utf32"},
+	62: {"utf16le_bin", "utf16le"},
+	63: {"binary", "binary"},
+	64: {"armscii8_bin", "armscii8"},
+	65: {"ascii_bin", "ascii"},
+	66: {"cp1250_bin", "cp1250"},
+	67: {"cp1256_bin", "cp1256"},
+	68: {"cp866_bin", "cp866"},
+	69: {"dec8_bin", "dec8"},
+	70: {"greek_bin", "greek"},
+	71: {"hebrew_bin", "hebrew"},
+	72: {"hp8_bin", "hp8"},
+	73: {"keybcs2_bin", "keybcs2"},
+	74: {"koi8r_bin", "koi8r"},
+	75: {"koi8u_bin", "koi8u"},
+	77: {"latin2_bin", "latin2"},
+	78: {"latin5_bin", "latin5"},
+	79: {"latin7_bin", "latin7"},
+	80: {"cp850_bin", "cp850"},
+	81: {"cp852_bin", "cp852"},
+	82: {"swe7_bin", "swe7"},
+	83: {"utf8_bin", "utf8"},
+	84: {"big5_bin", "big5"},
+	85: {"euckr_bin", "euckr"},
+	86: {"gb2312_bin", "gb2312"},
+	87: {"gbk_bin", "gbk"},
+	88: {"sjis_bin", "sjis"},
+	89: {"tis620_bin", "tis620"},
+	90: {"ucs2_bin", "ucs2"},
+	91: {"ujis_bin", "ujis"},
+	92: {"geostd8_general_ci", "geostd8"},
+	93: {"geostd8_bin", "geostd8"},
+	94: {"latin1_spanish_ci", "latin1"},
+	95: {"cp932_japanese_ci", "cp932"},
+	96: {"cp932_bin", "cp932"},
+	97: {"eucjpms_japanese_ci", "eucjpms"},
+	98: {"eucjpms_bin", "eucjpms"},
+	99: {"cp1250_polish_ci", "cp1250"},
+
+	223: {"utf8_general_mysql500_ci", "utf8"},
+	248: {"gb18030_chinese_ci", "gb18030"},
+	249: {"gb18030_bin", "gb18030"},
+	250: {"gb18030_unicode_520_ci", "gb18030"},
+	255: {"utf8mb4_0900_ai_ci", "utf8mb4"},
+	278: {"utf8mb4_0900_as_cs", "utf8mb4"},
+	305: {"utf8mb4_0900_as_ci", "utf8mb4"},
+	309: {"utf8mb4_0900_bin", "utf8mb4"},
+}
+
+// Default collations of character sets
+var charsetDefaults = map[string]uint16{
+	"big5": 1, "dec8": 3, "cp850": 4, "hp8": 6, "koi8r": 7, "latin1": 8,
+	"latin2": 9, "swe7": 10, "ascii": 11, "ujis": 12, "sjis": 13,
+	"hebrew": 16, "tis620": 18, "euckr": 19, "koi8u": 22, "gb2312": 24,
+	"greek": 25, "cp1250": 26, "gbk": 28, "latin5": 30, "armscii8": 32,
+	"utf8": 33, "ucs2": 35, "cp866": 36, "keybcs2": 37, "macce": 38,
+	"macroman": 39, "cp852": 40, "latin7": 41, "utf8mb4": 45, "cp1251": 51,
+	"utf16": 54, "utf16le": 56, "cp1256": 57, "cp1257": 59, "utf32": 60,
+	"binary": 63, "geostd8": 92, "cp932": 95, "eucjpms": 97, "gb18030": 248,
+}
+
+// Language specific Unicode collations. They have the same order for all
+// Unicode character sets.
+var unicodeCollations = []string{
+	"unicode", "icelandic", "latvian", "romanian", "slovenian", "polish",
+	"estonian", "spanish", "swedish", "turkish", "czech", "danish",
+	"lithuanian", "slovak", "spanish2", "roman", "persian", "esperanto",
+	"hungarian", "sinhala", "german2", "croatian", "unicode_520",
+	"vietnamese",
+}
+
+// Collation ids by name
+var collationIds = make(map[string]uint16)
+
+func init() {
+	for charset, first := range map[string]uint16{
+		"utf16": 101, "utf32": 160, "ucs2": 128, "utf8": 192, "utf8mb4": 224,
+	} {
+		for ii, name := range unicodeCollations {
+			id := first + uint16(ii)
+			collations[id] = collation{charset + "_" + name + "_ci", charset}
+		}
+	}
+	for id, c := range collations {
+		collationIds[c.name] = id
+	}
+}
+
+// Returns collation name for given id or "" if id is unknown.
+func CollationName(id uint16) string {
+	return collations[id].name
+}
+
+// Returns name of the character set of collation with given id or "" if id
+// is unknown.
+func CharsetName(id uint16) string {
+	return collations[id].charset
+}
+
+// Returns id of the collation with given name. If name is a character set
+// name returns id of its default collation. utf8mb3 is treated as utf8.
+func CollationId(name string) (id uint16, ok bool) {
+	name = strings.ToLower(name)
+	if name == "utf8mb3" || strings.HasPrefix(name, "utf8mb3_") {
+		name = "utf8" + name[7:]
+	}
+	if id, ok = charsetDefaults[name]; ok {
+		return
+	}
+	id, ok = collationIds[name]
+	return
+}
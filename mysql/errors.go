@@ -496,6 +496,7 @@ const (
 	ER_HOSTNAME                                = 1469
 	ER_WRONG_STRING_LENGTH                     = 1470
 	ER_NON_INSERTABLE_TABLE                    = 1471
+	ER_NEED_REPREPARE                          = 1615
 )
 
 type ClientError string
@@ -530,4 +531,26 @@ var (
 	ErrReadAfterEOR   = ClientError("previous ScanRow call returned io.EOF")
 	ErrOldProtocol    = ClientError("server does not support 4.1 protocol")
 	ErrAuthentication = ClientError("authentication error")
+	ErrBinlogEvent    = ClientError("malformed binlog event")
+	ErrBinlogChecksum = ClientError("binlog event checksum mismatch")
+	ErrBinlogNoTable  = ClientError("binlog rows event for unknown table")
+	ErrUnkCharset     = ClientError("unknown character set or collation")
+	ErrNotSupported   = ClientError("operation not supported by server")
+	ErrNoSavepoint    = ClientError("savepoint doesn't exist")
+	ErrTxOptions      = ClientError("invalid combination of transaction options")
+	ErrZeroInDate     = ClientError("zero month or day in date")
 )
+
+// Error used by Stmt.Bind (with coercion enabled) if a value can't be bound to
+// the parameter of type reported by the server.
+type BindError struct {
+	Param int         // Parameter number
+	Field *Field      // Parameter definition
+	Value interface{} // Bound value
+	Msg   string
+}
+
+func (e *BindError) Error() string {
+	return fmt.Sprintf("can't bind %T value to parameter #%d of type %s: %s",
+		e.Value, e.Param, e.Field.DatabaseTypeName(), e.Msg)
+}
@@ -1,5 +1,59 @@
 package mysql
 
+import "time"
+
+// Field flags
+const (
+	FLAG_NOT_NULL = 1 << iota
+	FLAG_PRI_KEY
+	FLAG_UNIQUE_KEY
+	FLAG_MULTIPLE_KEY
+	FLAG_BLOB
+	FLAG_UNSIGNED
+	FLAG_ZEROFILL
+	FLAG_BINARY
+	FLAG_ENUM
+	FLAG_AUTO_INCREMENT
+	FLAG_TIMESTAMP
+	FLAG_SET
+	FLAG_NO_DEFAULT_VALUE
+)
+
+// MySQL protocol types (values of Field.Type)
+const (
+	MYSQL_TYPE_DECIMAL     = 0x00
+	MYSQL_TYPE_TINY        = 0x01
+	MYSQL_TYPE_SHORT       = 0x02
+	MYSQL_TYPE_LONG        = 0x03
+	MYSQL_TYPE_FLOAT       = 0x04
+	MYSQL_TYPE_DOUBLE      = 0x05
+	MYSQL_TYPE_NULL        = 0x06
+	MYSQL_TYPE_TIMESTAMP   = 0x07
+	MYSQL_TYPE_LONGLONG    = 0x08
+	MYSQL_TYPE_INT24       = 0x09
+	MYSQL_TYPE_DATE        = 0x0a
+	MYSQL_TYPE_TIME        = 0x0b
+	MYSQL_TYPE_DATETIME    = 0x0c
+	MYSQL_TYPE_YEAR        = 0x0d
+	MYSQL_TYPE_NEWDATE     = 0x0e
+	MYSQL_TYPE_VARCHAR     = 0x0f
+	MYSQL_TYPE_BIT         = 0x10
+	MYSQL_TYPE_TIMESTAMP2  = 0x11
+	MYSQL_TYPE_DATETIME2   = 0x12
+	MYSQL_TYPE_TIME2       = 0x13
+	MYSQL_TYPE_JSON        = 0xf5
+	MYSQL_TYPE_NEWDECIMAL  = 0xf6
+	MYSQL_TYPE_ENUM        = 0xf7
+	MYSQL_TYPE_SET         = 0xf8
+	MYSQL_TYPE_TINY_BLOB   = 0xf9
+	MYSQL_TYPE_MEDIUM_BLOB = 0xfa
+	MYSQL_TYPE_LONG_BLOB   = 0xfb
+	MYSQL_TYPE_BLOB        = 0xfc
+	MYSQL_TYPE_VAR_STRING  = 0xfd
+	MYSQL_TYPE_STRING      = 0xfe
+	MYSQL_TYPE_GEOMETRY    = 0xff
+)
+
 type Field struct {
 	Catalog  string
 	Db       string
@@ -8,8 +62,142 @@ type Field struct {
 	Name     string
 	OrgName  string
 	DispLen  uint32
-	//  Charset  uint16
-	Flags uint16
-	Type  byte
-	Scale byte
+	Charset  uint16 // Collation id (see CollationName, CharsetName)
+	Flags    uint16
+	Type     byte
+	Scale    byte
+}
+
+// Returns name of the field collation (eg. utf8_general_ci).
+func (f *Field) CollationName() string {
+	return CollationName(f.Charset)
+}
+
+// Returns name of the field character set (eg. utf8).
+func (f *Field) CharsetName() string {
+	return CharsetName(f.Charset)
+}
+
+func (f *Field) IsNotNull() bool {
+	return f.Flags&FLAG_NOT_NULL != 0
+}
+
+func (f *Field) IsPrimaryKey() bool {
+	return f.Flags&FLAG_PRI_KEY != 0
+}
+
+func (f *Field) IsUnique() bool {
+	return f.Flags&FLAG_UNIQUE_KEY != 0
+}
+
+// True if field is a part of non-unique key
+func (f *Field) IsMultipleKey() bool {
+	return f.Flags&FLAG_MULTIPLE_KEY != 0
+}
+
+func (f *Field) IsUnsigned() bool {
+	return f.Flags&FLAG_UNSIGNED != 0
+}
+
+// True if field contains binary data (binary character set). For fields
+// with unknown character set it checks the BINARY flag.
+func (f *Field) IsBinary() bool {
+	if f.Charset != 0 {
+		return f.Charset == BINARY_COLLATION
+	}
+	return f.Flags&FLAG_BINARY != 0
+}
+
+func (f *Field) IsAutoIncrement() bool {
+	return f.Flags&FLAG_AUTO_INCREMENT != 0
+}
+
+func (f *Field) IsEnum() bool {
+	return f.Flags&FLAG_ENUM != 0 || f.Type == MYSQL_TYPE_ENUM
+}
+
+func (f *Field) IsSet() bool {
+	return f.Flags&FLAG_SET != 0 || f.Type == MYSQL_TYPE_SET
+}
+
+// SQL names of MySQL types
+var typeNames = map[byte]string{
+	MYSQL_TYPE_DECIMAL:     "DECIMAL",
+	MYSQL_TYPE_TINY:        "TINYINT",
+	MYSQL_TYPE_SHORT:       "SMALLINT",
+	MYSQL_TYPE_LONG:        "INT",
+	MYSQL_TYPE_FLOAT:       "FLOAT",
+	MYSQL_TYPE_DOUBLE:      "DOUBLE",
+	MYSQL_TYPE_NULL:        "NULL",
+	MYSQL_TYPE_TIMESTAMP:   "TIMESTAMP",
+	MYSQL_TYPE_LONGLONG:    "BIGINT",
+	MYSQL_TYPE_INT24:       "MEDIUMINT",
+	MYSQL_TYPE_DATE:        "DATE",
+	MYSQL_TYPE_TIME:        "TIME",
+	MYSQL_TYPE_DATETIME:    "DATETIME",
+	MYSQL_TYPE_YEAR:        "YEAR",
+	MYSQL_TYPE_NEWDATE:     "DATE",
+	MYSQL_TYPE_VARCHAR:     "VARCHAR",
+	MYSQL_TYPE_BIT:         "BIT",
+	MYSQL_TYPE_JSON:        "JSON",
+	MYSQL_TYPE_NEWDECIMAL:  "DECIMAL",
+	MYSQL_TYPE_ENUM:        "ENUM",
+	MYSQL_TYPE_SET:         "SET",
+	MYSQL_TYPE_TINY_BLOB:   "TINYTEXT",
+	MYSQL_TYPE_MEDIUM_BLOB: "MEDIUMTEXT",
+	MYSQL_TYPE_LONG_BLOB:   "LONGTEXT",
+	MYSQL_TYPE_BLOB:        "TEXT",
+	MYSQL_TYPE_VAR_STRING:  "VARCHAR",
+	MYSQL_TYPE_STRING:      "CHAR",
+	MYSQL_TYPE_GEOMETRY:    "GEOMETRY",
+}
+
+// Returns SQL name of the field type, eg: INT UNSIGNED, VARCHAR, VARBINARY,
+// BLOB, ENUM. Returns "" for unknown type.
+func (f *Field) DatabaseTypeName() string {
+	name := typeNames[f.Type]
+	switch f.Type {
+	case MYSQL_TYPE_TINY, MYSQL_TYPE_SHORT, MYSQL_TYPE_LONG,
+		MYSQL_TYPE_LONGLONG, MYSQL_TYPE_INT24, MYSQL_TYPE_FLOAT,
+		MYSQL_TYPE_DOUBLE, MYSQL_TYPE_DECIMAL, MYSQL_TYPE_NEWDECIMAL:
+		// Numeric types
+		if f.IsUnsigned() {
+			name += " UNSIGNED"
+		}
+	case MYSQL_TYPE_TINY_BLOB, MYSQL_TYPE_MEDIUM_BLOB, MYSQL_TYPE_LONG_BLOB,
+		MYSQL_TYPE_BLOB:
+		// Blob types
+		if f.IsBinary() {
+			name = name[:len(name)-4] + "BLOB"
+		}
+	case MYSQL_TYPE_VARCHAR, MYSQL_TYPE_VAR_STRING, MYSQL_TYPE_STRING:
+		// String types. ENUM and SET are returned as CHAR with flag
+		switch {
+		case f.Flags&FLAG_ENUM != 0:
+			name = "ENUM"
+		case f.Flags&FLAG_SET != 0:
+			name = "SET"
+		case f.IsBinary():
+			if f.Type == MYSQL_TYPE_STRING {
+				name = "BINARY"
+			} else {
+				name = "VARBINARY"
+			}
+		}
+	}
+	return name
+}
+
+// Returns v (value of this field) as string. DATETIME, TIMESTAMP and TIME
+// values are formatted with Scale fractional second digits, as MySQL does.
+func (f *Field) Format(v interface{}) string {
+	switch val := v.(type) {
+	case time.Time:
+		return TimeStringDec(val, int(f.Scale))
+	case Timestamp:
+		return TimeStringDec(val.Time, int(f.Scale))
+	case time.Duration:
+		return DurationStringDec(val, int(f.Scale))
+	}
+	return Row{v}.Str(0)
 }
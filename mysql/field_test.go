@@ -0,0 +1,74 @@
+package mysql
+
+import "testing"
+
+func TestCollations(t *testing.T) {
+	names := map[uint16]string{
+		8: "latin1_swedish_ci", 33: "utf8_general_ci", 63: "binary",
+		128: "ucs2_unicode_ci", 199: "utf8_spanish_ci",
+		247: "utf8mb4_vietnamese_ci", 255: "utf8mb4_0900_ai_ci",
+	}
+	for id, name := range names {
+		if n := CollationName(id); n != name {
+			t.Fatalf("Bad name of collation %d: %s != %s", id, n, name)
+		}
+		if i, ok := CollationId(name); !ok || i != id {
+			t.Fatalf("Bad id of collation %s: %d", name, i)
+		}
+	}
+	if id, _ := CollationId("UTF8MB3_BIN"); id != 83 {
+		t.Fatalf("Bad id of utf8mb3_bin: %d", id)
+	}
+	if c := CharsetName(224); c != "utf8mb4" {
+		t.Fatalf("Bad charset: %s", c)
+	}
+	if CollationName(1000) != "" {
+		t.Fatal("Unknown collation has name")
+	}
+}
+
+func TestFieldFlags(t *testing.T) {
+	f := Field{
+		Type: 0x03, Charset: BINARY_COLLATION,
+		Flags: FLAG_NOT_NULL | FLAG_PRI_KEY | FLAG_UNSIGNED |
+			FLAG_AUTO_INCREMENT | FLAG_BINARY,
+	}
+	if !f.IsNotNull() || !f.IsPrimaryKey() || f.IsUnique() ||
+		!f.IsUnsigned() || !f.IsAutoIncrement() || !f.IsBinary() ||
+		f.IsEnum() || f.IsSet() {
+		t.Fatalf("Bad flags: %+v", f)
+	}
+	// utf8_bin column has BINARY flag but isn't binary
+	f = Field{Type: 0xfd, Charset: 83, Flags: FLAG_BINARY}
+	if f.IsBinary() || f.CharsetName() != "utf8" {
+		t.Fatalf("Bad text field: %+v", f)
+	}
+}
+
+func TestDatabaseTypeName(t *testing.T) {
+	tests := []struct {
+		f   Field
+		exp string
+	}{
+		{Field{Type: 0x03, Flags: FLAG_UNSIGNED}, "INT UNSIGNED"},
+		{Field{Type: 0x09}, "MEDIUMINT"},
+		{Field{Type: 0xf6}, "DECIMAL"},
+		{Field{Type: 0x0d, Flags: FLAG_UNSIGNED}, "YEAR"},
+		{Field{Type: 0xfd, Charset: 33}, "VARCHAR"},
+		{Field{Type: 0xfd, Charset: BINARY_COLLATION}, "VARBINARY"},
+		{Field{Type: 0xfe, Charset: BINARY_COLLATION}, "BINARY"},
+		{Field{Type: 0xfe, Charset: 33, Flags: FLAG_ENUM}, "ENUM"},
+		{Field{Type: 0xfe, Charset: 33, Flags: FLAG_SET}, "SET"},
+		{Field{Type: 0xfc, Charset: 33}, "TEXT"},
+		{Field{Type: 0xfc, Charset: BINARY_COLLATION}, "BLOB"},
+		{Field{Type: 0xfb, Flags: FLAG_BINARY}, "LONGBLOB"},
+		{Field{Type: 0x0c, Charset: BINARY_COLLATION}, "DATETIME"},
+		{Field{Type: 0xf5, Charset: BINARY_COLLATION}, "JSON"},
+		{Field{Type: 0x20}, ""},
+	}
+	for _, test := range tests {
+		if n := test.f.DatabaseTypeName(); n != test.exp {
+			t.Fatalf("Bad type name: %s != %s", n, test.exp)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Spatial types for MyMySQL.
+//
+// MySQL returns GEOMETRY columns (and expects GEOMETRY parameters) in its
+// internal format: 4 byte little endian SRID followed by WKB representation
+// of the geometry. This package provides Go types for geometries, their
+// WKB/WKT encoding and decoding and conversion from/to MySQL format.
+package geo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// WKB geometry types
+const (
+	WKB_POINT              = 1
+	WKB_LINESTRING         = 2
+	WKB_POLYGON            = 3
+	WKB_MULTIPOINT         = 4
+	WKB_MULTILINESTRING    = 5
+	WKB_MULTIPOLYGON       = 6
+	WKB_GEOMETRYCOLLECTION = 7
+)
+
+// Any of Point, LineString, Polygon, MultiPoint, MultiLineString,
+// MultiPolygon, GeometryCollection.
+type Geometry interface {
+	// Returns WKB geometry type
+	WKBType() uint32
+	// Returns WKT representation of the geometry
+	String() string
+
+	writeWKB(buf *bytes.Buffer)
+	writeWKT(buf *bytes.Buffer)
+}
+
+type Point struct {
+	X, Y float64
+}
+
+type LineString []Point
+
+// The first ring is the exterior ring, others are interior rings (holes).
+type Polygon []LineString
+
+type MultiPoint []Point
+
+type MultiLineString []LineString
+
+type MultiPolygon []Polygon
+
+type GeometryCollection []Geometry
+
+func (Point) WKBType() uint32              { return WKB_POINT }
+func (LineString) WKBType() uint32         { return WKB_LINESTRING }
+func (Polygon) WKBType() uint32            { return WKB_POLYGON }
+func (MultiPoint) WKBType() uint32         { return WKB_MULTIPOINT }
+func (MultiLineString) WKBType() uint32    { return WKB_MULTILINESTRING }
+func (MultiPolygon) WKBType() uint32       { return WKB_MULTIPOLYGON }
+func (GeometryCollection) WKBType() uint32 { return WKB_GEOMETRYCOLLECTION }
+
+// Geometry with spatial reference system identifier. This is the form in
+// which MySQL stores geometry values.
+type Value struct {
+	SRID     uint32
+	Geometry Geometry
+}
+
+// Returns EWKT representation of the value: SRID=srid;WKT
+func (v Value) String() string {
+	if v.Geometry == nil {
+		return ""
+	}
+	return fmt.Sprintf("SRID=%d;%s", v.SRID, v.Geometry)
+}
+
+// Encodes geometry in MySQL internal format.
+func Encode(srid uint32, g Geometry) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, srid)
+	writeWKB(&buf, g)
+	return buf.Bytes()
+}
+
+// Decodes geometry from MySQL internal format.
+func Decode(buf []byte) (v Value, err error) {
+	if len(buf) < 4 {
+		return v, ErrWKB
+	}
+	v.SRID = binary.LittleEndian.Uint32(buf)
+	v.Geometry, err = DecodeWKB(buf[4:])
+	return
+}
+
+// Returns WKB representation of geometry (little endian).
+func EncodeWKB(g Geometry) []byte {
+	var buf bytes.Buffer
+	writeWKB(&buf, g)
+	return buf.Bytes()
+}
+
+func writeWKB(buf *bytes.Buffer, g Geometry) {
+	buf.WriteByte(1) // Little endian
+	binary.Write(buf, binary.LittleEndian, g.WKBType())
+	g.writeWKB(buf)
+}
+
+func writeU32(buf *bytes.Buffer, n int) {
+	binary.Write(buf, binary.LittleEndian, uint32(n))
+}
+
+func (p Point) writeWKB(buf *bytes.Buffer) {
+	binary.Write(buf, binary.LittleEndian, math.Float64bits(p.X))
+	binary.Write(buf, binary.LittleEndian, math.Float64bits(p.Y))
+}
+
+func (ls LineString) writeWKB(buf *bytes.Buffer) {
+	writeU32(buf, len(ls))
+	for _, p := range ls {
+		p.writeWKB(buf)
+	}
+}
+
+func (pg Polygon) writeWKB(buf *bytes.Buffer) {
+	writeU32(buf, len(pg))
+	for _, r := range pg {
+		r.writeWKB(buf)
+	}
+}
+
+func (mp MultiPoint) writeWKB(buf *bytes.Buffer) {
+	writeU32(buf, len(mp))
+	for _, p := range mp {
+		writeWKB(buf, p)
+	}
+}
+
+func (ml MultiLineString) writeWKB(buf *bytes.Buffer) {
+	writeU32(buf, len(ml))
+	for _, ls := range ml {
+		writeWKB(buf, ls)
+	}
+}
+
+func (mp MultiPolygon) writeWKB(buf *bytes.Buffer) {
+	writeU32(buf, len(mp))
+	for _, pg := range mp {
+		writeWKB(buf, pg)
+	}
+}
+
+func (gc GeometryCollection) writeWKB(buf *bytes.Buffer) {
+	writeU32(buf, len(gc))
+	for _, g := range gc {
+		writeWKB(buf, g)
+	}
+}
+
+var ErrWKB = errors.New("malformed WKB geometry")
+
+type wkbReader struct {
+	buf   []byte
+	order binary.ByteOrder
+}
+
+func (r *wkbReader) next(n int) []byte {
+	if len(r.buf) < n {
+		panic(ErrWKB)
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *wkbReader) u32() uint32 {
+	return r.order.Uint32(r.next(4))
+}
+
+// Returns number of elements checking that the rest of WKB can contain them
+func (r *wkbReader) count(min_size int) int {
+	n := r.u32()
+	if uint64(n)*uint64(min_size) > uint64(len(r.buf)) {
+		panic(ErrWKB)
+	}
+	return int(n)
+}
+
+func (r *wkbReader) point() Point {
+	x := math.Float64frombits(r.order.Uint64(r.next(8)))
+	y := math.Float64frombits(r.order.Uint64(r.next(8)))
+	return Point{x, y}
+}
+
+func (r *wkbReader) lineString() LineString {
+	ls := make(LineString, r.count(16))
+	for ii := range ls {
+		ls[ii] = r.point()
+	}
+	return ls
+}
+
+func (r *wkbReader) polygon() Polygon {
+	pg := make(Polygon, r.count(4))
+	for ii := range pg {
+		pg[ii] = r.lineString()
+	}
+	return pg
+}
+
+func (r *wkbReader) geometry() Geometry {
+	switch r.next(1)[0] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		panic(ErrWKB)
+	}
+	switch r.u32() {
+	case WKB_POINT:
+		return r.point()
+	case WKB_LINESTRING:
+		return r.lineString()
+	case WKB_POLYGON:
+		return r.polygon()
+	case WKB_MULTIPOINT:
+		mp := make(MultiPoint, r.count(5+16))
+		for ii := range mp {
+			p, ok := r.geometry().(Point)
+			if !ok {
+				panic(ErrWKB)
+			}
+			mp[ii] = p
+		}
+		return mp
+	case WKB_MULTILINESTRING:
+		ml := make(MultiLineString, r.count(5+4))
+		for ii := range ml {
+			ls, ok := r.geometry().(LineString)
+			if !ok {
+				panic(ErrWKB)
+			}
+			ml[ii] = ls
+		}
+		return ml
+	case WKB_MULTIPOLYGON:
+		mp := make(MultiPolygon, r.count(5+4))
+		for ii := range mp {
+			pg, ok := r.geometry().(Polygon)
+			if !ok {
+				panic(ErrWKB)
+			}
+			mp[ii] = pg
+		}
+		return mp
+	case WKB_GEOMETRYCOLLECTION:
+		gc := make(GeometryCollection, r.count(5))
+		for ii := range gc {
+			gc[ii] = r.geometry()
+		}
+		return gc
+	}
+	panic(ErrWKB)
+}
+
+// Decodes geometry from WKB (both byte orders are supported).
+func DecodeWKB(buf []byte) (g Geometry, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			if e != ErrWKB {
+				panic(e)
+			}
+			g, err = nil, ErrWKB
+		}
+	}()
+	r := &wkbReader{buf: buf}
+	g = r.geometry()
+	if len(r.buf) != 0 {
+		panic(ErrWKB)
+	}
+	return
+}
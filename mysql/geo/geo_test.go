@@ -0,0 +1,91 @@
+package geo
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+var geometries = []struct {
+	wkt string
+	g   Geometry
+}{
+	{"POINT(1 -2.5)", Point{1, -2.5}},
+	{"LINESTRING(0 0,1 1,2 0)", LineString{{0, 0}, {1, 1}, {2, 0}}},
+	{"POLYGON((0 0,4 0,4 4,0 0),(1 1,2 1,2 2,1 1))", Polygon{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 0}},
+		{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+	}},
+	{"MULTIPOINT((1 2),(3 4))", MultiPoint{{1, 2}, {3, 4}}},
+	{"MULTILINESTRING((0 0,1 1),(2 2,3 3))", MultiLineString{
+		{{0, 0}, {1, 1}}, {{2, 2}, {3, 3}},
+	}},
+	{"MULTIPOLYGON(((0 0,1 0,1 1,0 0)))", MultiPolygon{
+		{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+	}},
+	{"GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(0 0,1 1))", GeometryCollection{
+		Point{1, 2}, LineString{{0, 0}, {1, 1}},
+	}},
+	{"GEOMETRYCOLLECTION EMPTY", GeometryCollection(nil)},
+}
+
+func TestWKT(t *testing.T) {
+	for _, e := range geometries {
+		if s := e.g.String(); s != e.wkt {
+			t.Fatalf("Bad WKT: %s != %s", s, e.wkt)
+		}
+		g, err := ParseWKT(e.wkt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(g, e.g) {
+			t.Fatalf("Bad geometry parsed from %s: %#v", e.wkt, g)
+		}
+	}
+	g, err := ParseWKT(" multipoint ( 1 2 , 3e1 4 ) ")
+	if err != nil || !reflect.DeepEqual(g, MultiPoint{{1, 2}, {30, 4}}) {
+		t.Fatalf("Bad geometry: %v %v", g, err)
+	}
+	for _, s := range []string{"POINT(1)", "LINESTRING(0 0", "CIRCLE(1 1)",
+		"POINT(1 2) x"} {
+		if _, err := ParseWKT(s); err != ErrWKT {
+			t.Fatalf("No error for %s", s)
+		}
+	}
+}
+
+func TestWKB(t *testing.T) {
+	for _, e := range geometries {
+		wkb := EncodeWKB(e.g)
+		g, err := DecodeWKB(wkb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.String() != e.wkt {
+			t.Fatalf("Bad geometry decoded from WKB of %s: %#v", e.wkt, g)
+		}
+		if _, err := DecodeWKB(wkb[:len(wkb)-1]); err != ErrWKB {
+			t.Fatalf("Truncated WKB of %s decoded", e.wkt)
+		}
+	}
+	// Big endian POINT(1 2)
+	be := []byte{0, 0, 0, 0, 1, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0,
+		0x40, 0, 0, 0, 0, 0, 0, 0}
+	if g, err := DecodeWKB(be); err != nil || g != (Point{1, 2}) {
+		t.Fatalf("Bad big endian point: %v %v", g, err)
+	}
+}
+
+func TestMySQLFormat(t *testing.T) {
+	buf := Encode(4326, Point{1, 2})
+	if !bytes.Equal(buf[:9], []byte{0xe6, 0x10, 0, 0, 1, 1, 0, 0, 0}) {
+		t.Fatalf("Bad header: %v", buf[:9])
+	}
+	v, err := Decode(buf)
+	if err != nil || v.SRID != 4326 || v.Geometry != (Point{1, 2}) {
+		t.Fatalf("Bad value: %+v %v", v, err)
+	}
+	if s := v.String(); s != "SRID=4326;POINT(1 2)" {
+		t.Fatalf("Bad EWKT: %s", s)
+	}
+}
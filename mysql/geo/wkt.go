@@ -0,0 +1,237 @@
+package geo
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Returns WKT representation of geometry (in the form used by MySQL 8.0).
+func WKT(g Geometry) string {
+	var buf bytes.Buffer
+	writeWKT(&buf, g)
+	return buf.String()
+}
+
+func (p Point) String() string               { return WKT(p) }
+func (ls LineString) String() string         { return WKT(ls) }
+func (pg Polygon) String() string            { return WKT(pg) }
+func (mp MultiPoint) String() string         { return WKT(mp) }
+func (ml MultiLineString) String() string    { return WKT(ml) }
+func (mp MultiPolygon) String() string       { return WKT(mp) }
+func (gc GeometryCollection) String() string { return WKT(gc) }
+
+var wktNames = map[uint32]string{
+	WKB_POINT:              "POINT",
+	WKB_LINESTRING:         "LINESTRING",
+	WKB_POLYGON:            "POLYGON",
+	WKB_MULTIPOINT:         "MULTIPOINT",
+	WKB_MULTILINESTRING:    "MULTILINESTRING",
+	WKB_MULTIPOLYGON:       "MULTIPOLYGON",
+	WKB_GEOMETRYCOLLECTION: "GEOMETRYCOLLECTION",
+}
+
+func writeWKT(buf *bytes.Buffer, g Geometry) {
+	buf.WriteString(wktNames[g.WKBType()])
+	g.writeWKT(buf)
+}
+
+func writeCoords(buf *bytes.Buffer, p Point) {
+	buf.WriteString(strconv.FormatFloat(p.X, 'f', -1, 64))
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatFloat(p.Y, 'f', -1, 64))
+}
+
+// Writes list of elements or EMPTY if n == 0
+func writeList(buf *bytes.Buffer, n int, elem func(ii int)) {
+	if n == 0 {
+		buf.WriteString(" EMPTY")
+		return
+	}
+	buf.WriteByte('(')
+	for ii := 0; ii < n; ii++ {
+		if ii > 0 {
+			buf.WriteByte(',')
+		}
+		elem(ii)
+	}
+	buf.WriteByte(')')
+}
+
+func (p Point) writeWKT(buf *bytes.Buffer) {
+	buf.WriteByte('(')
+	writeCoords(buf, p)
+	buf.WriteByte(')')
+}
+
+func (ls LineString) writeWKT(buf *bytes.Buffer) {
+	writeList(buf, len(ls), func(ii int) { writeCoords(buf, ls[ii]) })
+}
+
+func (pg Polygon) writeWKT(buf *bytes.Buffer) {
+	writeList(buf, len(pg), func(ii int) { pg[ii].writeWKT(buf) })
+}
+
+func (mp MultiPoint) writeWKT(buf *bytes.Buffer) {
+	writeList(buf, len(mp), func(ii int) { mp[ii].writeWKT(buf) })
+}
+
+func (ml MultiLineString) writeWKT(buf *bytes.Buffer) {
+	writeList(buf, len(ml), func(ii int) { ml[ii].writeWKT(buf) })
+}
+
+func (mp MultiPolygon) writeWKT(buf *bytes.Buffer) {
+	writeList(buf, len(mp), func(ii int) { mp[ii].writeWKT(buf) })
+}
+
+func (gc GeometryCollection) writeWKT(buf *bytes.Buffer) {
+	writeList(buf, len(gc), func(ii int) { writeWKT(buf, gc[ii]) })
+}
+
+var ErrWKT = errors.New("malformed WKT geometry")
+
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) != -1 {
+		p.pos++
+	}
+}
+
+func (p *wktParser) peek() byte {
+	p.skipSpace()
+	if p.pos == len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *wktParser) expect(c byte) {
+	if p.peek() != c {
+		panic(ErrWKT)
+	}
+	p.pos++
+}
+
+func (p *wktParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos] | 0x20 // Lower case
+		if c < 'a' || c > 'z' {
+			break
+		}
+		p.pos++
+	}
+	return strings.ToUpper(p.s[start:p.pos])
+}
+
+func (p *wktParser) number() float64 {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) &&
+		strings.IndexByte("+-.0123456789eE", p.s[p.pos]) != -1 {
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		panic(ErrWKT)
+	}
+	return f
+}
+
+func (p *wktParser) coords() Point {
+	x := p.number()
+	return Point{x, p.number()}
+}
+
+// Parses "(elem, ...)" or "EMPTY"
+func (p *wktParser) list(elem func()) {
+	if p.peek() != '(' {
+		if p.word() != "EMPTY" {
+			panic(ErrWKT)
+		}
+		return
+	}
+	p.pos++
+	for {
+		elem()
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	p.expect(')')
+}
+
+func (p *wktParser) lineString() (ls LineString) {
+	p.list(func() { ls = append(ls, p.coords()) })
+	return
+}
+
+func (p *wktParser) polygon() (pg Polygon) {
+	p.list(func() { pg = append(pg, p.lineString()) })
+	return
+}
+
+func (p *wktParser) geometry() Geometry {
+	switch p.word() {
+	case "POINT":
+		p.expect('(')
+		pt := p.coords()
+		p.expect(')')
+		return pt
+	case "LINESTRING":
+		return p.lineString()
+	case "POLYGON":
+		return p.polygon()
+	case "MULTIPOINT":
+		var mp MultiPoint
+		p.list(func() {
+			// Both MULTIPOINT((1 2),(3 4)) and MULTIPOINT(1 2,3 4)
+			if p.peek() == '(' {
+				p.pos++
+				mp = append(mp, p.coords())
+				p.expect(')')
+			} else {
+				mp = append(mp, p.coords())
+			}
+		})
+		return mp
+	case "MULTILINESTRING":
+		var ml MultiLineString
+		p.list(func() { ml = append(ml, p.lineString()) })
+		return ml
+	case "MULTIPOLYGON":
+		var mp MultiPolygon
+		p.list(func() { mp = append(mp, p.polygon()) })
+		return mp
+	case "GEOMETRYCOLLECTION", "GEOMCOLLECTION":
+		var gc GeometryCollection
+		p.list(func() { gc = append(gc, p.geometry()) })
+		return gc
+	}
+	panic(ErrWKT)
+}
+
+// Parses WKT representation of geometry.
+func ParseWKT(s string) (g Geometry, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			if e != ErrWKT {
+				panic(e)
+			}
+			g, err = nil, ErrWKT
+		}
+	}()
+	p := &wktParser{s: s}
+	g = p.geometry()
+	if p.peek() != 0 {
+		panic(ErrWKT)
+	}
+	return
+}
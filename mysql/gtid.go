@@ -0,0 +1,299 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Server UUID (SID) - the source part of MySQL GTID
+type Uuid [16]byte
+
+func (u Uuid) String() string {
+	h := hex.EncodeToString(u[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" +
+		h[20:32]
+}
+
+// Parses UUID in format XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX. Dashes are
+// optional.
+func ParseUuid(str string) (u Uuid, err error) {
+	h := strings.Replace(strings.TrimSpace(str), "-", "", -1)
+	if len(h) != 32 {
+		err = errors.New("Invalid UUID string: " + str)
+		return
+	}
+	if _, err = hex.Decode(u[:], []byte(h)); err != nil {
+		err = errors.New("Invalid UUID string: " + str)
+	}
+	return
+}
+
+// Closed interval of transaction numbers: [Start, Stop]
+type GtidInterval struct {
+	Start, Stop int64
+}
+
+// Set of MySQL global transaction identifiers in form
+// UUID:interval[:interval]...[,UUID:interval...]
+//
+// The zero value is an empty set ready to use.
+type GtidSet struct {
+	sets map[Uuid][]GtidInterval // Intervals are sorted and not overlapping
+}
+
+// Returns new empty GTID set.
+func NewGtidSet() *GtidSet {
+	return &GtidSet{sets: make(map[Uuid][]GtidInterval)}
+}
+
+// Parses GTID set in format returned by @@gtid_executed, for example:
+//
+//	3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5:11,
+//	4e11fa47-71ca-11e1-9e33-c80aa9429562:23
+//
+// Whitespaces (including new lines) are ignored. Empty string gives empty set.
+func ParseGtidSet(str string) (gs *GtidSet, err error) {
+	gs = NewGtidSet()
+	str = strings.Map(
+		func(r rune) rune {
+			if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+				return -1
+			}
+			return r
+		},
+		str,
+	)
+	if str == "" {
+		return
+	}
+	for _, s := range strings.Split(str, ",") {
+		parts := strings.Split(s, ":")
+		if len(parts) < 2 {
+			goto invalid
+		}
+		var sid Uuid
+		if sid, err = ParseUuid(parts[0]); err != nil {
+			return
+		}
+		for _, p := range parts[1:] {
+			var iv GtidInterval
+			se := strings.SplitN(p, "-", 2)
+			if iv.Start, err = strconv.ParseInt(se[0], 10, 64); err != nil {
+				return
+			}
+			iv.Stop = iv.Start
+			if len(se) == 2 {
+				if iv.Stop, err = strconv.ParseInt(se[1], 10, 64); err != nil {
+					return
+				}
+			}
+			if iv.Start < 1 || iv.Stop < iv.Start {
+				goto invalid
+			}
+			gs.AddInterval(sid, iv)
+		}
+	}
+	return
+
+invalid:
+	err = errors.New("Invalid GTID set string: " + str)
+	return
+}
+
+func (gs *GtidSet) sortedSids() []Uuid {
+	sids := make([]Uuid, 0, len(gs.sets))
+	for sid := range gs.sets {
+		sids = append(sids, sid)
+	}
+	sort.Slice(sids, func(i, j int) bool {
+		return bytes.Compare(sids[i][:], sids[j][:]) < 0
+	})
+	return sids
+}
+
+// Returns GTID set in MySQL format (UUIDs in lexical order).
+func (gs *GtidSet) String() string {
+	if gs == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	for ii, sid := range gs.sortedSids() {
+		if ii > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(sid.String())
+		for _, iv := range gs.sets[sid] {
+			if iv.Start == iv.Stop {
+				fmt.Fprintf(&buf, ":%d", iv.Start)
+			} else {
+				fmt.Fprintf(&buf, ":%d-%d", iv.Start, iv.Stop)
+			}
+		}
+	}
+	return buf.String()
+}
+
+// True if set doesn't contain any GTID.
+func (gs *GtidSet) IsEmpty() bool {
+	return gs == nil || len(gs.sets) == 0
+}
+
+// Returns intervals for sid
+func (gs *GtidSet) Intervals(sid Uuid) []GtidInterval {
+	if gs == nil {
+		return nil
+	}
+	return gs.sets[sid]
+}
+
+// Adds all transactions from iv to the set.
+func (gs *GtidSet) AddInterval(sid Uuid, iv GtidInterval) {
+	if gs.sets == nil {
+		gs.sets = make(map[Uuid][]GtidInterval)
+	}
+	ivs := gs.sets[sid]
+	// Find the first interval that can be merged with iv
+	n := sort.Search(len(ivs), func(i int) bool {
+		return ivs[i].Stop+1 >= iv.Start
+	})
+	// Merge all intervals that overlap or are adjacent to iv
+	m := n
+	for m < len(ivs) && ivs[m].Start <= iv.Stop+1 {
+		if ivs[m].Start < iv.Start {
+			iv.Start = ivs[m].Start
+		}
+		if ivs[m].Stop > iv.Stop {
+			iv.Stop = ivs[m].Stop
+		}
+		m++
+	}
+	out := make([]GtidInterval, 0, len(ivs)-(m-n)+1)
+	out = append(out, ivs[:n]...)
+	out = append(out, iv)
+	out = append(out, ivs[m:]...)
+	gs.sets[sid] = out
+}
+
+// Adds one GTID (sid:gno) to the set.
+func (gs *GtidSet) Add(sid Uuid, gno int64) {
+	gs.AddInterval(sid, GtidInterval{gno, gno})
+}
+
+// Adds all GTIDs from other set to gs.
+func (gs *GtidSet) Union(other *GtidSet) {
+	if other == nil {
+		return
+	}
+	for sid, ivs := range other.sets {
+		for _, iv := range ivs {
+			gs.AddInterval(sid, iv)
+		}
+	}
+}
+
+// True if gs contains sid:gno.
+func (gs *GtidSet) ContainsGtid(sid Uuid, gno int64) bool {
+	ivs := gs.Intervals(sid)
+	n := sort.Search(len(ivs), func(i int) bool { return ivs[i].Stop >= gno })
+	return n < len(ivs) && ivs[n].Start <= gno
+}
+
+// True if gs contains all GTIDs from other set.
+func (gs *GtidSet) Contains(other *GtidSet) bool {
+	if other == nil {
+		return true
+	}
+	for sid, oivs := range other.sets {
+		ivs := gs.Intervals(sid)
+		for _, oiv := range oivs {
+			n := sort.Search(len(ivs), func(i int) bool {
+				return ivs[i].Stop >= oiv.Start
+			})
+			if n == len(ivs) || ivs[n].Start > oiv.Start ||
+				ivs[n].Stop < oiv.Stop {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// True if both sets contain the same GTIDs.
+func (gs *GtidSet) Equal(other *GtidSet) bool {
+	return gs.Contains(other) && other.Contains(gs)
+}
+
+// Returns a deep copy of gs.
+func (gs *GtidSet) Clone() *GtidSet {
+	c := NewGtidSet()
+	c.Union(gs)
+	return c
+}
+
+// Encodes set in binary format used by COM_BINLOG_DUMP_GTID.
+func (gs *GtidSet) Encode() []byte {
+	var buf bytes.Buffer
+	u64 := func(v uint64) {
+		for ii := uint(0); ii < 64; ii += 8 {
+			buf.WriteByte(byte(v >> ii))
+		}
+	}
+	sids := gs.sortedSids()
+	u64(uint64(len(sids)))
+	for _, sid := range sids {
+		buf.Write(sid[:])
+		ivs := gs.sets[sid]
+		u64(uint64(len(ivs)))
+		for _, iv := range ivs {
+			// In binary format the end of interval is exclusive
+			u64(uint64(iv.Start))
+			u64(uint64(iv.Stop + 1))
+		}
+	}
+	return buf.Bytes()
+}
+
+// Decodes set encoded in binary format (see Encode).
+func DecodeGtidSet(buf []byte) (gs *GtidSet, err error) {
+	gs = NewGtidSet()
+	u64 := func() (v uint64) {
+		for ii := uint(0); ii < 64; ii += 8 {
+			v |= uint64(buf[0]) << ii
+			buf = buf[1:]
+		}
+		return
+	}
+	if len(buf) < 8 {
+		goto invalid
+	}
+	for n := u64(); n > 0; n-- {
+		if len(buf) < 16+8 {
+			goto invalid
+		}
+		var sid Uuid
+		copy(sid[:], buf)
+		buf = buf[16:]
+		m := u64()
+		if m > uint64(len(buf))/16 {
+			goto invalid
+		}
+		for ; m > 0; m-- {
+			iv := GtidInterval{int64(u64()), 0}
+			iv.Stop = int64(u64()) - 1
+			if iv.Start < 1 || iv.Stop < iv.Start {
+				goto invalid
+			}
+			gs.AddInterval(sid, iv)
+		}
+	}
+	return
+
+invalid:
+	err = errors.New("Invalid binary GTID set")
+	return
+}
@@ -0,0 +1,106 @@
+package mysql
+
+import (
+	"testing"
+)
+
+var gtidSets = []sio{
+	sio{"", ""},
+	sio{
+		"3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5",
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5",
+	},
+	sio{
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562:7:1-3:4-5:9-10",
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5:7:9-10",
+	},
+	sio{
+		"4e11fa47-71ca-11e1-9e33-c80aa9429562:23,\n" +
+			" 3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5:3-8",
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-8," +
+			"4e11fa47-71ca-11e1-9e33-c80aa9429562:23",
+	},
+}
+
+func TestGtidSetString(t *testing.T) {
+	for _, ex := range gtidSets {
+		gs, err := ParseGtidSet(ex.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := gs.String(); s != ex.out {
+			t.Fatalf("Wrong GTID set: '%s' != '%s'", s, ex.out)
+		}
+		bgs, err := DecodeGtidSet(gs.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bgs.Equal(gs) {
+			t.Fatalf("Wrong binary coding: '%s' != '%s'", bgs, gs)
+		}
+	}
+}
+
+func TestGtidSetInvalid(t *testing.T) {
+	for _, s := range []string{
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562",
+		"3e11fa47-71ca-11e1-9e33:1-5",
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562:5-1",
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562:0",
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562:a",
+	} {
+		if _, err := ParseGtidSet(s); err == nil {
+			t.Fatalf("No error for '%s'", s)
+		}
+	}
+}
+
+func TestDecodeGtidSetInvalid(t *testing.T) {
+	gs, _ := ParseGtidSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5")
+	enc := gs.Encode()
+	// Number of intervals is at offset 8+16
+	// 1<<60 intervals: 16 * count overflows uint64
+	huge := append([]byte(nil), enc...)
+	copy(huge[24:32], []byte{0, 0, 0, 0, 0, 0, 0, 0x10})
+	big := append([]byte(nil), enc...)
+	big[24] = 2
+	for _, buf := range [][]byte{enc[:len(enc)-1], enc[:20], huge, big} {
+		if _, err := DecodeGtidSet(buf); err == nil {
+			t.Fatalf("No error for %v", buf)
+		}
+	}
+}
+
+func TestGtidSetUnionContains(t *testing.T) {
+	a, _ := ParseGtidSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5:10")
+	b, _ := ParseGtidSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:6-9:11," +
+		"4e11fa47-71ca-11e1-9e33-c80aa9429562:1")
+	if a.Contains(b) || b.Contains(a) {
+		t.Fatal("Disjoint sets contain each other")
+	}
+	u := a.Clone()
+	u.Union(b)
+	exp := "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-11," +
+		"4e11fa47-71ca-11e1-9e33-c80aa9429562:1"
+	if u.String() != exp {
+		t.Fatalf("Wrong union: '%s' != '%s'", u, exp)
+	}
+	if !u.Contains(a) || !u.Contains(b) || a.Contains(u) {
+		t.Fatal("Wrong containment of union")
+	}
+	if a.String() != "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5:10" {
+		t.Fatal("Clone shares data with original set")
+	}
+	sid, _ := ParseUuid("4e11fa47-71ca-11e1-9e33-c80aa9429562")
+	if !u.ContainsGtid(sid, 1) || u.ContainsGtid(sid, 2) {
+		t.Fatal("Wrong ContainsGtid result")
+	}
+	u.Add(sid, 2)
+	if !u.ContainsGtid(sid, 2) || len(u.Intervals(sid)) != 1 {
+		t.Fatal("Wrong Add result")
+	}
+	var z GtidSet
+	if !z.IsEmpty() || !u.Contains(&z) || z.Contains(u) {
+		t.Fatal("Wrong handling of empty set")
+	}
+}
@@ -22,11 +22,21 @@ type Conn interface {
 	Close() error
 	IsConnected() bool
 	Reconnect() error
+	ResetSession() error
+	PrepareCached(sql string) (Stmt, error)
+	ExecCached(sql string, params ...interface{}) ([]Row, Result, error)
+	Pipeline() Pipeline
+	CallProc(name string, params ...interface{}) ([][]Row, Result, error)
 	Use(dbname string) error
 	Register(sql string)
 	SetMaxPktSize(new_size int) int
+	SetStmtCacheSize(size int) int
+	SetCharset(name string) error
+	ChangeCharset(name string) error
+	SetAttr(name, value string)
 
 	Begin() (Transaction, error)
+	BeginTx(opts TxOptions) (Transaction, error)
 }
 
 type Transaction interface {
@@ -36,6 +46,11 @@ type Transaction interface {
 	Rollback() error
 	Do(st Stmt) Stmt
 	IsValid() bool
+
+	Begin() (Transaction, error)
+	Savepoint(name string) error
+	RollbackTo(name string) error
+	ReleaseSavepoint(name string) error
 }
 
 type Stmt interface {
@@ -50,6 +65,9 @@ type Stmt interface {
 	NumField() int
 	NumParam() int
 	WarnCount() int
+	ParamFields() []*Field
+	SetCoercion(on bool)
+	ExecBatch(rows [][]interface{}) ([]BatchResult, error)
 
 	Exec(params ...interface{}) ([]Row, Result, error)
 	ExecFirst(params ...interface{}) (Row, Result, error)
@@ -70,6 +88,7 @@ type Result interface {
 	AffectedRows() uint64
 	InsertId() uint64
 	WarnCount() int
+	SessionState() *SessionState
 
 	MakeRow() Row
 	GetRows() ([]Row, error)
@@ -78,4 +97,25 @@ type Result interface {
 	GetLastRow() (Row, error)
 }
 
+type Pipeline interface {
+	Query(sql string, params ...interface{}) error
+	Exec(stmt Stmt, params ...interface{}) error
+	Len() int
+	Run() ([]PipelineResult, error)
+}
+
+// Result of a command executed by Pipeline.Run
+type PipelineResult struct {
+	Rows []Row
+	Res  Result
+	Err  error // Error returned by the server for this command
+}
+
+// Result of one execution of a statement by Stmt.ExecBatch
+type BatchResult struct {
+	AffectedRows uint64
+	InsertId     uint64
+	Err          error // Error returned by the server for this execution
+}
+
 var New func(proto, laddr, raddr, user, passwd string, db ...string) Conn
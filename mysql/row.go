@@ -2,8 +2,10 @@ package mysql
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/ziutek/mymysql/mysql/geo"
 	"math"
 	"os"
 	"reflect"
@@ -210,11 +212,17 @@ func (tr Row) TimeErr(nn int, loc *time.Location) (t time.Time, err error) {
 	case nil:
 		// nop
 	case time.Time:
+		if IsZeroTime(data) {
+			return ZeroTime, nil
+		}
 		t = data.In(loc)
 		if loc != time.Local {
 			t = convertTime(t, loc)
 		}
 	case Date:
+		if data.IsZeroInDate() {
+			return t, ErrZeroInDate
+		}
 		t = data.Time(loc)
 	case []byte:
 		t, err = ParseTime(string(data), loc)
@@ -248,6 +256,9 @@ func (tr Row) LocaltimeErr(nn int) (t time.Time, err error) {
 	case time.Time:
 		t = data
 	case Date:
+		if data.IsZeroInDate() {
+			return t, ErrZeroInDate
+		}
 		t = data.Time(time.Local)
 	case []byte:
 		t, err = ParseTime(string(data), time.Local)
@@ -476,3 +487,46 @@ func (tr Row) ForceFloat(nn int) (val float64) {
 	val, _ = tr.FloatErr(nn)
 	return
 }
+
+// Get the nn-th value (JSON document) and unmarshal it into v. NULL is
+// treated as JSON null. Return error if value isn't a text or unmarshal fails.
+func (tr Row) JSON(nn int, v interface{}) error {
+	switch data := tr[nn].(type) {
+	case nil:
+		return json.Unmarshal([]byte("null"), v)
+	case []byte:
+		return json.Unmarshal(data, v)
+	case string:
+		return json.Unmarshal([]byte(data), v)
+	}
+	return os.ErrInvalid
+}
+
+// Get the nn-th value (geometry in MySQL internal format) and decode it
+// (zero if NULL). Returns error if value isn't a valid geometry.
+func (tr Row) GeometryErr(nn int) (val geo.Value, err error) {
+	switch data := tr[nn].(type) {
+	case nil:
+		// nop
+	case []byte:
+		val, err = geo.Decode(data)
+	default:
+		err = os.ErrInvalid
+	}
+	return
+}
+
+// As GeometryErr but panics if conversion is impossible.
+func (tr Row) Geometry(nn int) (val geo.Value) {
+	val, err := tr.GeometryErr(nn)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// It is like GeometryErr but returns zero value if conversion is impossible.
+func (tr Row) ForceGeometry(nn int) (val geo.Value) {
+	val, _ = tr.GeometryErr(nn)
+	return
+}
@@ -0,0 +1,59 @@
+package mysql
+
+// Kinds of session state changes
+const (
+	SESSION_TRACK_SYSTEM_VARIABLES = 1 << iota
+	SESSION_TRACK_SCHEMA
+	SESSION_TRACK_STATE_CHANGE
+	SESSION_TRACK_GTIDS
+	SESSION_TRACK_TRANSACTION_CHARACTERISTICS
+	SESSION_TRACK_TRANSACTION_STATE
+)
+
+// Session state changes reported by the server (see session_track_*
+// system variables).
+type SessionState struct {
+	Changes   uint              // Kinds of reported changes (SESSION_TRACK_*)
+	Schema    string            // New default database
+	Variables map[string]string // New values of system variables
+	GTIDs     string            // GTIDs of committed transactions
+
+	// Statements that restore characteristics of the current transaction
+	TxCharacteristics string
+
+	// Transaction state (see session_track_transaction_info)
+	TxState string
+}
+
+// Returns true if changes of kind (SESSION_TRACK_*) were reported.
+func (ss *SessionState) Changed(kind uint) bool {
+	return ss != nil && ss.Changes&kind != 0
+}
+
+// Adds changes from ns to ss. Newer values replace older ones.
+func (ss *SessionState) Merge(ns *SessionState) {
+	if ns == nil {
+		return
+	}
+	if ns.Changed(SESSION_TRACK_SYSTEM_VARIABLES) {
+		if ss.Variables == nil {
+			ss.Variables = make(map[string]string)
+		}
+		for k, v := range ns.Variables {
+			ss.Variables[k] = v
+		}
+	}
+	if ns.Changed(SESSION_TRACK_SCHEMA) {
+		ss.Schema = ns.Schema
+	}
+	if ns.Changed(SESSION_TRACK_GTIDS) {
+		ss.GTIDs = ns.GTIDs
+	}
+	if ns.Changed(SESSION_TRACK_TRANSACTION_CHARACTERISTICS) {
+		ss.TxCharacteristics = ns.TxCharacteristics
+	}
+	if ns.Changed(SESSION_TRACK_TRANSACTION_STATE) {
+		ss.TxState = ns.TxState
+	}
+	ss.Changes |= ns.Changes
+}
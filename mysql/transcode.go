@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"sync"
+	"unicode/utf8"
+)
+
+// Reverse tables for EncodeString (created on first use)
+var (
+	sbcsEncoders = make(map[string]map[rune]byte)
+	sbcsMutex    sync.Mutex
+)
+
+func sbcsEncoder(charset string) map[rune]byte {
+	sbcsMutex.Lock()
+	defer sbcsMutex.Unlock()
+
+	enc := sbcsEncoders[charset]
+	if enc == nil {
+		enc = make(map[rune]byte)
+		for ii, r := range sbcsTables[charset] {
+			if r != utf8.RuneError {
+				enc[r] = byte(0x80 + ii)
+			}
+		}
+		sbcsEncoders[charset] = enc
+	}
+	return enc
+}
+
+// Returns true if strings in character set of collation with given id can be
+// converted from/to UTF-8 by DecodeString/EncodeString (single byte
+// character sets like latin1, latin2, cp1250).
+func CanTranscode(id uint16) bool {
+	return sbcsTables[CharsetName(id)] != nil
+}
+
+// Converts b from character set of collation with given id to UTF-8 string.
+// If the character set isn't supported by CanTranscode returns string(b).
+func DecodeString(id uint16, b []byte) string {
+	table := sbcsTables[CharsetName(id)]
+	if table == nil {
+		return string(b)
+	}
+	buf := make([]byte, 0, len(b))
+	var rb [utf8.UTFMax]byte
+	for _, c := range b {
+		if c < 0x80 {
+			buf = append(buf, c)
+			continue
+		}
+		n := utf8.EncodeRune(rb[:], table[c-0x80])
+		buf = append(buf, rb[:n]...)
+	}
+	return string(buf)
+}
+
+// Converts UTF-8 string s to character set of collation with given id.
+// Characters that don't exist in this character set are replaced by '?'. If
+// the character set isn't supported by CanTranscode returns []byte(s).
+func EncodeString(id uint16, s string) []byte {
+	charset := CharsetName(id)
+	if sbcsTables[charset] == nil {
+		return []byte(s)
+	}
+	enc := sbcsEncoder(charset)
+	buf := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 0x80 {
+			buf = append(buf, byte(r))
+		} else if c, ok := enc[r]; ok {
+			buf = append(buf, c)
+		} else {
+			buf = append(buf, '?')
+		}
+	}
+	return buf
+}
+
+// Returns v (value of this field) as UTF-8 string. Text values in single byte
+// character sets are converted using DecodeString.
+func (f *Field) DecodeString(v []byte) string {
+	if f.IsBinary() {
+		return string(v)
+	}
+	return DecodeString(f.Charset, v)
+}
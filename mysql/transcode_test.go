@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"bytes"
+	"testing"
+)
+
+var transcodeTests = []struct {
+	charset string
+	enc     []byte
+	dec     string
+}{
+	{"latin1", []byte("caf\xe9 \x80"), "café €"},
+	{"latin2", []byte("\xbf\xf3\xb3w"), "żółw"},
+	{"cp1250", []byte("\xbf\xf3\xb3w \x9c"), "żółw ś"},
+	{"cp1251", []byte("\xcf\xf0\xe8\xe2\xe5\xf2"), "Привет"},
+	{"koi8r", []byte("\xf0\xd2\xc9\xd7\xc5\xd4"), "Привет"},
+	{"greek", []byte("\xe1\xe2\xe3"), "αβγ"},
+	{"utf8", []byte("żółw"), "żółw"},
+}
+
+func TestTranscode(t *testing.T) {
+	for _, tt := range transcodeTests {
+		id, _ := CollationId(tt.charset)
+		if dec := DecodeString(id, tt.enc); dec != tt.dec {
+			t.Errorf("%s DecodeString(%q)=%q exp=%q", tt.charset, tt.enc, dec, tt.dec)
+		}
+		if enc := EncodeString(id, tt.dec); !bytes.Equal(enc, tt.enc) {
+			t.Errorf("%s EncodeString(%q)=%q exp=%q", tt.charset, tt.dec, enc, tt.enc)
+		}
+	}
+	id, _ := CollationId("latin1")
+	if enc := EncodeString(id, "żx"); string(enc) != "?x" {
+		t.Errorf("EncodeString: unknown character encoded as %q", enc)
+	}
+	if !CanTranscode(id) || CanTranscode(33) || CanTranscode(BINARY_COLLATION) {
+		t.Error("CanTranscode returned bad value")
+	}
+	f := Field{Charset: id}
+	if s := f.DecodeString([]byte("\xe9")); s != "é" {
+		t.Errorf("Field.DecodeString=%q", s)
+	}
+	f.Charset = BINARY_COLLATION
+	if s := f.DecodeString([]byte("\xe9")); s != "\xe9" {
+		t.Errorf("Field.DecodeString of binary field=%q", s)
+	}
+}
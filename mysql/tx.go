@@ -0,0 +1,82 @@
+package mysql
+
+import (
+	"strings"
+)
+
+// Transaction isolation level
+type IsolationLevel int
+
+const (
+	ISOLATION_DEFAULT IsolationLevel = iota // Isolation level of the session
+	ISOLATION_READ_UNCOMMITTED
+	ISOLATION_READ_COMMITTED
+	ISOLATION_REPEATABLE_READ
+	ISOLATION_SERIALIZABLE
+)
+
+var isolationNames = []string{
+	"", "READ UNCOMMITTED", "READ COMMITTED", "REPEATABLE READ", "SERIALIZABLE",
+}
+
+// Returns isolation level as used in SQL statements (empty string for
+// ISOLATION_DEFAULT).
+func (l IsolationLevel) String() string {
+	if l < 0 || int(l) >= len(isolationNames) {
+		return "unknown"
+	}
+	return isolationNames[l]
+}
+
+// Options of transaction started by BeginTx
+type TxOptions struct {
+	Isolation IsolationLevel
+
+	ReadOnly  bool // START TRANSACTION READ ONLY
+	ReadWrite bool // START TRANSACTION READ WRITE
+
+	// START TRANSACTION WITH CONSISTENT SNAPSHOT (only for REPEATABLE READ)
+	ConsistentSnapshot bool
+}
+
+// Returns ErrTxOptions if options are invalid or can't be used together.
+func (o TxOptions) Validate() error {
+	switch {
+	case o.Isolation < ISOLATION_DEFAULT || o.Isolation > ISOLATION_SERIALIZABLE:
+		return ErrTxOptions
+	case o.ReadOnly && o.ReadWrite:
+		return ErrTxOptions
+	case o.ConsistentSnapshot && o.Isolation != ISOLATION_DEFAULT &&
+		o.Isolation != ISOLATION_REPEATABLE_READ:
+		// Consistent snapshot is only possible for REPEATABLE READ
+		return ErrTxOptions
+	}
+	return nil
+}
+
+// Returns statements that start transaction with options o: SET TRANSACTION
+// ISOLATION LEVEL (if isolation level is specified) and START TRANSACTION.
+func (o TxOptions) Statements() ([]string, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	var stmts, chars []string
+	if o.Isolation != ISOLATION_DEFAULT {
+		// Sets isolation level of the next transaction only
+		stmts = append(stmts, "SET TRANSACTION ISOLATION LEVEL "+o.Isolation.String())
+	}
+	if o.ConsistentSnapshot {
+		chars = append(chars, "WITH CONSISTENT SNAPSHOT")
+	}
+	if o.ReadOnly {
+		chars = append(chars, "READ ONLY")
+	}
+	if o.ReadWrite {
+		chars = append(chars, "READ WRITE")
+	}
+	start := "START TRANSACTION"
+	if len(chars) != 0 {
+		start += " " + strings.Join(chars, ", ")
+	}
+	return append(stmts, start), nil
+}
@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"reflect"
+	"testing"
+)
+
+type txTest struct {
+	opts  TxOptions
+	stmts []string // nil if options are invalid
+}
+
+var txTests = []txTest{
+	{TxOptions{}, []string{"START TRANSACTION"}},
+	{
+		TxOptions{Isolation: ISOLATION_READ_COMMITTED, ReadOnly: true},
+		[]string{
+			"SET TRANSACTION ISOLATION LEVEL READ COMMITTED",
+			"START TRANSACTION READ ONLY",
+		},
+	},
+	{
+		TxOptions{ConsistentSnapshot: true, ReadWrite: true},
+		[]string{"START TRANSACTION WITH CONSISTENT SNAPSHOT, READ WRITE"},
+	},
+	{
+		TxOptions{Isolation: ISOLATION_REPEATABLE_READ, ConsistentSnapshot: true},
+		[]string{
+			"SET TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+			"START TRANSACTION WITH CONSISTENT SNAPSHOT",
+		},
+	},
+	{TxOptions{ReadOnly: true, ReadWrite: true}, nil},
+	{TxOptions{Isolation: ISOLATION_SERIALIZABLE, ConsistentSnapshot: true}, nil},
+	{TxOptions{Isolation: ISOLATION_SERIALIZABLE + 1}, nil},
+}
+
+func TestTxOptions(t *testing.T) {
+	for _, tt := range txTests {
+		stmts, err := tt.opts.Statements()
+		if tt.stmts == nil {
+			if err != ErrTxOptions {
+				t.Errorf("%+v: ErrTxOptions expected: %v", tt.opts, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: %v", tt.opts, err)
+			continue
+		}
+		if !reflect.DeepEqual(stmts, tt.stmts) {
+			t.Errorf("%+v: %q, expected %q", tt.opts, stmts, tt.stmts)
+		}
+	}
+}
@@ -23,27 +23,35 @@ func (dd Date) IsZero() bool {
 	return dd.Day == 0 && dd.Month == 0 && dd.Year == 0
 }
 
+// True if month or day (but not both with year) is zero, eg. 2014-00-00.
+// Such date can't be represented by time.Time.
+func (dd Date) IsZeroInDate() bool {
+	return (dd.Month == 0 || dd.Day == 0) && !dd.IsZero()
+}
+
 // Converts Date to time.Time using loc location.
-// Converts MySQL zero to time.Time zero.
+// Converts MySQL zero to ZeroTime. Zero-in-date (see IsZeroInDate) is
+// normalized by time.Date, use Row.TimeErr to detect it.
 func (dd Date) Time(loc *time.Location) (t time.Time) {
-	if !dd.IsZero() {
-		t = time.Date(
-			int(dd.Year), time.Month(dd.Month), int(dd.Day),
-			0, 0, 0, 0,
-			loc,
-		)
+	if dd.IsZero() {
+		return ZeroTime
 	}
-	return
+	return time.Date(
+		int(dd.Year), time.Month(dd.Month), int(dd.Day),
+		0, 0, 0, 0,
+		loc,
+	)
 }
 
 // Converts Date to time.Time using Local location.
-// Converts MySQL zero to time.Time zero.
+// Converts MySQL zero to ZeroTime.
 func (dd Date) Localtime() time.Time {
 	return dd.Time(time.Local)
 }
 
-// Convert string date in format YYYY-MM-DD to Date.
-// Leading and trailing spaces are ignored. If format is invalid returns zero.
+// Convert string date in format YYYY-MM-DD to Date. Zero month or day are
+// accepted (see IsZeroInDate). Leading and trailing spaces are ignored. If
+// format is invalid returns zero.
 func ParseDate(str string) (dd Date, err error) {
 	str = strings.TrimSpace(str)
 	if str == "0000-00-00" {
@@ -61,13 +69,13 @@ func ParseDate(str string) (dd Date, err error) {
 	if m, err = strconv.Atoi(str[5:7]); err != nil {
 		return
 	}
-	if m < 1 || m > 12 {
+	if m < 0 || m > 12 {
 		goto invalid
 	}
 	if d, err = strconv.Atoi(str[8:10]); err != nil {
 		return
 	}
-	if d < 1 || d > 31 {
+	if d < 0 || d > 31 {
 		goto invalid
 	}
 	dd.Year = int16(y)
@@ -83,34 +91,73 @@ invalid:
 // Sandard MySQL datetime format
 const TimeFormat = "2006-01-02 15:04:05.000000000"
 
-// Returns t as string in MySQL format Converts time.Time zero to MySQL zero.
-func TimeString(t time.Time) string {
-	if t.IsZero() {
-		return "0000-00-00 00:00:00"
+// MySQL zero datetime (0000-00-00 00:00:00) as time.Time. Its year is out of
+// MySQL range so it doesn't collide with any real value (time.Time zero is
+// a valid 0001-01-01 00:00:00 UTC). Use IsZeroTime to test for it.
+var ZeroTime = time.Date(-1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// True if t is ZeroTime (in any location).
+func IsZeroTime(t time.Time) bool {
+	return t.Equal(ZeroTime)
+}
+
+// Returns fractional part of MySQL time/datetime value with dec digits.
+// If dec < 0 or dec > 6 (value of unknown precision) returns six digits or
+// nothing if us == 0.
+func fracString(us, dec int) string {
+	if dec < 0 || dec > 6 {
+		if us == 0 {
+			return ""
+		}
+		dec = 6
 	}
-	if t.Nanosecond() == 0 {
-		return t.Format(TimeFormat[:19])
+	if dec == 0 {
+		return ""
 	}
-	return t.Format(TimeFormat)
+	return fmt.Sprintf(".%06d", us)[:dec+1]
 }
 
-// Parses string datetime in TimeFormat using loc location.
-// Converts MySQL zero to time.Time zero.
+// Returns t as string in MySQL format with dec fractional second digits
+// (use Field.Scale). Fractional part is truncated to microseconds. If dec is
+// out of 0-6 range microseconds are emitted only if they are nonzero.
+// Converts ZeroTime to MySQL zero.
+func TimeStringDec(t time.Time, dec int) string {
+	if IsZeroTime(t) {
+		return "0000-00-00 00:00:00" + fracString(0, dec)
+	}
+	return t.Format(TimeFormat[:19]) + fracString(t.Nanosecond()/1e3, dec)
+}
+
+// Returns t as string in MySQL format. Microseconds are emitted only if they
+// are nonzero. Converts ZeroTime to MySQL zero.
+func TimeString(t time.Time) string {
+	return TimeStringDec(t, -1)
+}
+
+// True if str is MySQL zero date/datetime (with optional fractional part)
+func isZeroTimeStr(str string) bool {
+	return strings.HasPrefix(str, "0000-00-00") &&
+		strings.Trim(str[10:], "0: .") == ""
+}
+
+// Parses string datetime in TimeFormat (with 0-9 fractional second digits)
+// or date in YYYY-MM-DD format using loc location.
+// Converts MySQL zero to ZeroTime. Returns ErrZeroInDate if only month or day
+// is zero.
 func ParseTime(str string, loc *time.Location) (t time.Time, err error) {
 	str = strings.TrimSpace(str)
+	if isZeroTimeStr(str) {
+		return ZeroTime, nil
+	}
+	if len(str) >= 10 && (str[5:7] == "00" || str[8:10] == "00") {
+		return t, ErrZeroInDate
+	}
 	format := TimeFormat[:19]
-	switch len(str) {
-	case 10:
-		if str == "0000-00-00" {
-			return
-		}
+	if len(str) == 10 {
 		format = format[:10]
-	case 19:
-		if str == "0000-00-00 00:00:00" {
-			return
-		}
 	}
-	// Don't expect 0000-00-00 00:00:00.0+
+	// Fractional part is accepted by time.Parse even if format doesn't
+	// contain it.
 	t, err = time.Parse(format, str)
 	if err == nil && loc != time.UTC {
 		t = convertTime(t, loc)
@@ -118,32 +165,45 @@ func ParseTime(str string, loc *time.Location) (t time.Time, err error) {
 	return
 }
 
-// Convert time.Duration to string representation of mysql.TIME
-func DurationString(d time.Duration) string {
-	sign := 1
+// Convert time.Duration to string representation of mysql.TIME with dec
+// fractional second digits (use Field.Scale). Fractional part is truncated to
+// microseconds. If dec is out of 0-6 range microseconds are emitted only if
+// they are nonzero.
+func DurationStringDec(d time.Duration, dec int) string {
+	sign := ""
 	if d < 0 {
-		sign = -1
+		sign = "-"
 		d = -d
 	}
-	ns := int(d % 1e9)
+	us := int(d % 1e9 / 1e3)
 	d /= 1e9
 	sec := int(d % 60)
 	d /= 60
 	min := int(d % 60)
-	hour := int(d/60) * sign
-	if ns == 0 {
-		return fmt.Sprintf("%d:%02d:%02d", hour, min, sec)
-	}
-	return fmt.Sprintf("%d:%02d:%02d.%09d", hour, min, sec, ns)
+	hour := int64(d / 60)
+	return fmt.Sprintf(
+		"%s%d:%02d:%02d%s", sign, hour, min, sec, fracString(us, dec),
+	)
 }
 
-// Parse duration from MySQL string format [+-]H+:MM:SS[.UUUUUUUUU].
-// Leading and trailing spaces are ignored. If format is invalid returns nil.
+// Convert time.Duration to string representation of mysql.TIME.
+// Microseconds are emitted only if they are nonzero.
+func DurationString(d time.Duration) string {
+	return DurationStringDec(d, -1)
+}
+
+// Parse duration from MySQL string format [+-]H+:MM:SS[.UUUUUUUUU] (0-9
+// fractional second digits). Leading and trailing spaces are ignored. If
+// format is invalid returns nil.
 func ParseDuration(str string) (dur time.Duration, err error) {
 	str = strings.TrimSpace(str)
 	orig := str
-	// Check sign
 	sign := int64(1)
+	var i, d int64
+	if str == "" {
+		goto invalid
+	}
+	// Check sign
 	switch str[0] {
 	case '-':
 		sign = -1
@@ -151,7 +211,6 @@ func ParseDuration(str string) (dur time.Duration, err error) {
 	case '+':
 		str = str[1:]
 	}
-	var i, d int64
 	// Find houre
 	if nn := strings.IndexRune(str, ':'); nn != -1 {
 		if i, err = strconv.ParseInt(str[0:nn], 10, 64); err != nil {
@@ -162,7 +221,7 @@ func ParseDuration(str string) (dur time.Duration, err error) {
 	} else {
 		goto invalid
 	}
-	if len(str) != 5 && len(str) != 15 || str[2] != ':' {
+	if len(str) < 5 || len(str) > 15 || len(str) == 6 || str[2] != ':' {
 		goto invalid
 	}
 	if i, err = strconv.ParseInt(str[0:2], 10, 64); err != nil {
@@ -180,13 +239,17 @@ func ParseDuration(str string) (dur time.Duration, err error) {
 	}
 	d += i
 	d *= 1e9
-	if len(str) == 15 {
+	if len(str) > 5 {
 		if str[5] != '.' {
 			goto invalid
 		}
-		if i, err = strconv.ParseInt(str[6:15], 10, 64); err != nil {
+		frac := str[6:] + "000000000"[len(str)-6:]
+		if i, err = strconv.ParseInt(frac, 10, 64); err != nil {
 			return
 		}
+		if frac[0] < '0' || frac[0] > '9' {
+			goto invalid
+		}
 		d += i
 	}
 	dur = time.Duration(d * sign)
@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"github.com/ziutek/mymysql/mysql/geo"
 	"testing"
 	"time"
 )
@@ -23,6 +24,8 @@ func checkRow(t *testing.T, examples []sio, conv func(string) interface{}) {
 var dates = []sio{
 	sio{"2121-11-22", "2121-11-22"},
 	sio{"0000-00-00", "0000-00-00"},
+	sio{"2014-00-00", "2014-00-00"},
+	sio{"2014-13-01", "Invalid MySQL DATE string: 2014-13-01"},
 	sio{" 1234-12-18  ", "1234-12-18"},
 	sio{"\t1234-12-18 \r\n", "1234-12-18"},
 }
@@ -45,7 +48,12 @@ var datetimes = []sio{
 	sio{"2000-11-11", "2000-11-11 00:00:00"},
 	sio{"0000-00-00 00:00:00", "0000-00-00 00:00:00"},
 	sio{"0000-00-00", "0000-00-00 00:00:00"},
-	sio{"2000-11-22 11:11:11.000111222", "2000-11-22 11:11:11.000111222"},
+	sio{"2000-11-22 11:11:11.000111222", "2000-11-22 11:11:11.000111"},
+	sio{"2000-11-22 11:11:11.123", "2000-11-22 11:11:11.123000"},
+	sio{"0000-00-00 00:00:00.000000", "0000-00-00 00:00:00"},
+	sio{"0001-01-01 00:00:00", "0001-01-01 00:00:00"},
+	sio{"2014-00-00 00:00:00", ErrZeroInDate.Error()},
+	sio{"2014-03-00", ErrZeroInDate.Error()},
 }
 
 func TestConvTime(t *testing.T) {
@@ -65,8 +73,12 @@ var times = []sio{
 	sio{"+112:23:45", "112:23:45"},
 	sio{"1:60:00", "invalid MySQL TIME string: 1:60:00"},
 	sio{"1:00:60", "invalid MySQL TIME string: 1:00:60"},
-	sio{"1:23:45.000111333", "1:23:45.000111333"},
-	sio{"-1:23:45.000111333", "-1:23:45.000111333"},
+	sio{"1:23:45.000111333", "1:23:45.000111"},
+	sio{"-1:23:45.000111333", "-1:23:45.000111"},
+	sio{"-0:00:01.5", "-0:00:01.500000"},
+	sio{"838:59:59.000001", "838:59:59.000001"},
+	sio{"1:23:45.", "invalid MySQL TIME string: 1:23:45."},
+	sio{"", "invalid MySQL TIME string: "},
 }
 
 func TestConvDuration(t *testing.T) {
@@ -80,3 +92,64 @@ func TestConvDuration(t *testing.T) {
 	}
 	checkRow(t, times, conv)
 }
+
+func TestFieldFormat(t *testing.T) {
+	tt := time.Date(2014, 3, 4, 5, 6, 7, 123456789, time.Local)
+	dd := -(26*time.Hour + 5*time.Second + 120*time.Microsecond)
+	tests := []struct {
+		scale byte
+		val   interface{}
+		exp   string
+	}{
+		{0, tt, "2014-03-04 05:06:07"},
+		{3, tt, "2014-03-04 05:06:07.123"},
+		{6, Timestamp{tt}, "2014-03-04 05:06:07.123456"},
+		{31, tt, "2014-03-04 05:06:07.123456"},
+		{2, ZeroTime, "0000-00-00 00:00:00.00"},
+		{0, time.Time{}, "0001-01-01 00:00:00"},
+		{0, dd, "-26:00:05"},
+		{4, dd, "-26:00:05.0001"},
+		{6, int64(12), "12"},
+	}
+	for _, test := range tests {
+		f := Field{Scale: test.scale}
+		if s := f.Format(test.val); s != test.exp {
+			t.Fatalf("Bad format: '%s' != '%s'", s, test.exp)
+		}
+	}
+}
+
+func TestRowJSON(t *testing.T) {
+	row := Row{[]byte(`{"a":[1,2],"b":"x"}`), nil, int8(1)}
+	var v struct {
+		A []int
+		B string
+	}
+	if err := row.JSON(0, &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.A) != 2 || v.A[1] != 2 || v.B != "x" {
+		t.Fatalf("Bad JSON value: %+v", v)
+	}
+	m := map[string]int{"a": 1}
+	if err := row.JSON(1, &m); err != nil || m != nil {
+		t.Fatalf("NULL isn't unmarshaled as null: %v %v", err, m)
+	}
+	if err := row.JSON(2, &m); err == nil {
+		t.Fatal("No error for not text value")
+	}
+}
+
+func TestRowGeometry(t *testing.T) {
+	pt := geo.Point{X: 1, Y: 2}
+	row := Row{geo.Encode(4326, pt), nil, []byte{1, 2}}
+	if v := row.Geometry(0); v.SRID != 4326 || v.Geometry != pt {
+		t.Fatalf("Bad geometry: %v", v)
+	}
+	if v := row.Geometry(1); v.Geometry != nil {
+		t.Fatalf("NULL isn't decoded as zero value: %v", v)
+	}
+	if _, err := row.GeometryErr(2); err == nil {
+		t.Fatal("No error for malformed geometry")
+	}
+}
@@ -28,6 +28,7 @@ func syntaxError(ln int) error {
 //	# optional: DbName	test
 //	# optional: DbEncd	utf8	
 //	# optional: DbLaddr	127.0.0.1
+//	# optional connection attributes: DbAttr	program_name myapp
 //
 //	# Your options (returned in unk)
 //
@@ -41,6 +42,7 @@ func NewFromCF(cfgFile string) (con Conn, unk map[string]string, err error) {
 	br := bufio.NewReader(cf)
 	um := make(map[string]string)
 	var proto, laddr, raddr, user, pass, name, encd string
+	attrs := make(map[string]string)
 	for i := 1; ; i++ {
 		buf, isPrefix, e := br.ReadLine()
 		if e != nil {
@@ -83,6 +85,13 @@ func NewFromCF(cfgFile string) (con Conn, unk map[string]string, err error) {
 			name = l
 		case "DbEncd":
 			encd = l
+		case "DbAttr":
+			n = strings.IndexFunc(l, unicode.IsSpace)
+			if n == -1 {
+				err = syntaxError(i)
+				return
+			}
+			attrs[l[:n]] = strings.TrimLeftFunc(l[n:], unicode.IsSpace)
 		default:
 			um[v] = l
 		}
@@ -97,8 +106,11 @@ func NewFromCF(cfgFile string) (con Conn, unk map[string]string, err error) {
 	} else {
 		con = New(proto, laddr, raddr, user, pass)
 	}
+	for n, v := range attrs {
+		con.SetAttr(n, v)
+	}
 	if encd != "" {
-		con.Register(fmt.Sprintf("SET NAMES %s", encd))
+		err = con.SetCharset(encd)
 	}
 	return
 }
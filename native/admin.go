@@ -0,0 +1,216 @@
+package native
+
+import (
+	"github.com/ziutek/mymysql/mysql"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Flags for Refresh
+const (
+	REFRESH_GRANT   = 0x01 // Reload grant tables
+	REFRESH_LOG     = 0x02 // Flush logs
+	REFRESH_TABLES  = 0x04 // Close all tables
+	REFRESH_HOSTS   = 0x08 // Flush host cache
+	REFRESH_STATUS  = 0x10 // Reset status variables
+	REFRESH_THREADS = 0x20 // Flush thread cache
+	REFRESH_SLAVE   = 0x40 // Reset master info and restart slave thread
+	REFRESH_MASTER  = 0x80 // Remove all binary logs
+)
+
+// Shutdown levels
+const (
+	SHUTDOWN_DEFAULT               = 0x00
+	SHUTDOWN_WAIT_CONNECTIONS      = 0x01 // Wait for existing connections
+	SHUTDOWN_WAIT_TRANSACTIONS     = 0x02 // Wait for existing transactions
+	SHUTDOWN_WAIT_UPDATES          = 0x08 // Wait for existing updates
+	SHUTDOWN_WAIT_ALL_BUFFERS      = 0x10 // Flush InnoDB buffers and other
+	SHUTDOWN_WAIT_CRITICAL_BUFFERS = 0x11 // Don't flush InnoDB buffers
+)
+
+// Options for COM_SET_OPTION
+const (
+	_MYSQL_OPTION_MULTI_STATEMENTS_ON  = 0
+	_MYSQL_OPTION_MULTI_STATEMENTS_OFF = 1
+)
+
+// Reads response to command that returns OK or EOF packet.
+func (my *Conn) getStatusResponse() {
+	pr := my.newPktReader()
+	switch readByte(pr) {
+	case 255:
+		my.getErrorPacket(pr)
+	case 254:
+		_, my.status = my.getEofPacket(pr)
+	case 0:
+		my.getOkPacket(pr)
+	default:
+		panic(mysql.ErrUnkResultPkt)
+	}
+}
+
+// Sends command that returns OK or EOF packet.
+func (my *Conn) statusCmd(cmd byte, argv ...interface{}) (err error) {
+	defer catchError(&err)
+
+	if my.net_conn == nil {
+		return mysql.ErrNotConn
+	}
+	if my.unreaded_reply {
+		return mysql.ErrUnreadedReply
+	}
+	my.sendCmd(cmd, argv...)
+	my.getStatusResponse()
+	return
+}
+
+// Server statistics (see mysqladmin status)
+type Statistics struct {
+	Uptime      time.Duration
+	Threads     int
+	Questions   uint64
+	SlowQueries uint64
+	Opens       uint64
+	FlushTables uint64
+	OpenTables  uint64
+	QueriesAvg  float64 // Queries per second
+	Raw         string  // Statistics string returned by server
+}
+
+// Parses statistics string, eg: "Uptime: 26  Threads: 1  Questions: 2 ..."
+func parseStatistics(raw string) *Statistics {
+	st := &Statistics{Raw: raw}
+	for _, kv := range strings.Split(raw, "  ") {
+		n := strings.Index(kv, ": ")
+		if n == -1 {
+			continue
+		}
+		val := strings.TrimSpace(kv[n+2:])
+		u, _ := strconv.ParseUint(val, 10, 64)
+		switch strings.TrimSpace(kv[:n]) {
+		case "Uptime":
+			st.Uptime = time.Duration(u) * time.Second
+		case "Threads":
+			st.Threads = int(u)
+		case "Questions":
+			st.Questions = u
+		case "Slow queries":
+			st.SlowQueries = u
+		case "Opens":
+			st.Opens = u
+		case "Flush tables":
+			st.FlushTables = u
+		case "Open tables":
+			st.OpenTables = u
+		case "Queries per second avg":
+			st.QueriesAvg, _ = strconv.ParseFloat(val, 64)
+		}
+	}
+	return st
+}
+
+// Returns server statistics (COM_STATISTICS).
+func (my *Conn) Statistics() (st *Statistics, err error) {
+	defer catchError(&err)
+
+	if my.net_conn == nil {
+		return nil, mysql.ErrNotConn
+	}
+	if my.unreaded_reply {
+		return nil, mysql.ErrUnreadedReply
+	}
+	my.sendCmd(_COM_STATISTICS)
+	pr := my.newPktReader()
+	if readByte(pr) == 255 {
+		my.getErrorPacket(pr)
+	}
+	pr.unreadByte()
+	raw := string(pr.readAll())
+	if my.Debug {
+		log.Printf("[%2d ->] Statistics packet: %s", my.seq-1, raw)
+	}
+	return parseStatistics(raw), nil
+}
+
+// Returns list of server threads (COM_PROCESS_INFO, like SHOW PROCESSLIST).
+func (my *Conn) ProcessInfo() (res mysql.Result, err error) {
+	defer catchError(&err)
+
+	if my.net_conn == nil {
+		return nil, mysql.ErrNotConn
+	}
+	if my.unreaded_reply {
+		return nil, mysql.ErrUnreadedReply
+	}
+	my.sendCmd(_COM_PROCESS_INFO)
+	res = my.getResponse()
+	return
+}
+
+// Kills the server thread (connection) with given id (COM_PROCESS_KILL).
+func (my *Conn) Kill(thr_id uint32) error {
+	return my.statusCmd(_COM_PROCESS_KILL, thr_id)
+}
+
+// Returns definitions of columns of the table (COM_FIELD_LIST). Optional
+// wildcard (LIKE pattern) selects columns by name.
+func (my *Conn) ListFields(table, wildcard string) (fields []*mysql.Field, err error) {
+	defer catchError(&err)
+
+	if my.net_conn == nil {
+		return nil, mysql.ErrNotConn
+	}
+	if my.unreaded_reply {
+		return nil, mysql.ErrUnreadedReply
+	}
+	my.sendCmd(_COM_FIELD_LIST, table, wildcard)
+	for {
+		pr := my.newPktReader()
+		pkt0 := readByte(pr)
+		switch {
+		case pkt0 == 255:
+			my.getErrorPacket(pr)
+		case pkt0 == 254 && pr.last:
+			_, my.status = my.getEofPacket(pr)
+			return
+		}
+		if my.Debug {
+			log.Printf("[%2d ->] Field list packet:", my.seq-1)
+		}
+		pr.unreadByte()
+		field := readField(pr)
+		pr.readAll() // Default value
+		if my.Debug {
+			log.Printf(tab8s+"Name=\"%s\" Type=0x%x", field.Name, field.Type)
+		}
+		fields = append(fields, field)
+	}
+}
+
+// Flushes tables, logs, caches... (COM_REFRESH). flags is a combination of
+// REFRESH_* flags.
+func (my *Conn) Refresh(flags byte) error {
+	return my.statusCmd(_COM_REFRESH, flags)
+}
+
+// Enables/disables multiple statements in one query (COM_SET_OPTION).
+func (my *Conn) SetMultiStatements(on bool) error {
+	opt := uint16(_MYSQL_OPTION_MULTI_STATEMENTS_OFF)
+	if on {
+		opt = _MYSQL_OPTION_MULTI_STATEMENTS_ON
+	}
+	return my.statusCmd(_COM_SET_OPTION, opt)
+}
+
+// Asks the server to shut down (COM_SHUTDOWN). level is one of SHUTDOWN_*
+// constants. MySQL 8.0 doesn't support this command (use SHUTDOWN query).
+func (my *Conn) Shutdown(level byte) error {
+	return my.statusCmd(_COM_SHUTDOWN, level)
+}
+
+// Asks the server to write debug information to its error log (COM_DEBUG).
+func (my *Conn) DumpDebugInfo() error {
+	return my.statusCmd(_COM_DEBUG)
+}
@@ -0,0 +1,185 @@
+package native
+
+import (
+	"bytes"
+	"github.com/ziutek/mymysql/mysql"
+	"testing"
+	"time"
+)
+
+var (
+	okPkt  = []byte{0, 0, 0, 2, 0, 0, 0}
+	eofPkt = []byte{0xfe, 0, 0, 2, 0}
+	errPkt = []byte("\xff\x46\x04#HY000Unknown thread id: 7")
+)
+
+// Checks command packet written to out
+func checkCmd(t *testing.T, out *bytes.Buffer, exp []byte) {
+	pkt := out.Bytes()
+	if len(pkt) < 4 || !bytes.Equal(pkt[4:], exp) {
+		t.Fatalf("command packet: %q exp payload: %q", pkt, exp)
+	}
+	out.Reset()
+}
+
+func TestStatistics(t *testing.T) {
+	var out bytes.Buffer
+	raw := "Uptime: 26  Threads: 3  Questions: 12  Slow queries: 1  " +
+		"Opens: 33  Flush tables: 1  Open tables: 26  " +
+		"Queries per second avg: 0.461"
+	my := testConn(0, &out, []byte(raw))
+	st, err := my.Statistics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCmd(t, &out, []byte{_COM_STATISTICS})
+	exp := Statistics{
+		Uptime: 26 * time.Second, Threads: 3, Questions: 12, SlowQueries: 1,
+		Opens: 33, FlushTables: 1, OpenTables: 26, QueriesAvg: 0.461, Raw: raw,
+	}
+	if *st != exp {
+		t.Fatalf("statistics: %+v exp: %+v", *st, exp)
+	}
+}
+
+func TestKill(t *testing.T) {
+	var out bytes.Buffer
+	my := testConn(0, &out, okPkt)
+	if err := my.Kill(7); err != nil {
+		t.Fatal(err)
+	}
+	checkCmd(t, &out, []byte{_COM_PROCESS_KILL, 7, 0, 0, 0})
+
+	my = testConn(0, &out, errPkt)
+	err := my.Kill(7)
+	if e, ok := err.(*mysql.Error); !ok || e.Code != mysql.ER_NO_SUCH_THREAD {
+		t.Fatalf("Kill returned %v", err)
+	}
+}
+
+func TestListFields(t *testing.T) {
+	for _, caps := range []uint32{0, _CLIENT_DEPRECATE_EOF} {
+		var out bytes.Buffer
+		end := eofPkt
+		if caps != 0 {
+			end = []byte{0xfe, 0, 0, 2, 0, 0, 0}
+		}
+		my := testConn(
+			caps, &out,
+			append(fieldPkt("id", MYSQL_TYPE_LONG, 63), 0xfb),
+			append(fieldPkt("name", MYSQL_TYPE_VAR_STRING, 33), 1, 'x'),
+			end,
+		)
+		fields, err := my.ListFields("t", "%")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkCmd(t, &out, []byte("\x04t\x00%"))
+		if len(fields) != 2 || fields[0].Name != "id" ||
+			fields[1].Type != MYSQL_TYPE_VAR_STRING || fields[1].Charset != 33 {
+			t.Fatalf("bad fields: %+v", fields)
+		}
+	}
+}
+
+func TestStatusCommands(t *testing.T) {
+	var out bytes.Buffer
+	tests := []struct {
+		cmd  func(my *Conn) error
+		pkt  []byte
+		resp []byte
+	}{
+		{
+			func(my *Conn) error { return my.Refresh(REFRESH_TABLES | REFRESH_LOG) },
+			[]byte{_COM_REFRESH, 0x06}, okPkt,
+		},
+		{
+			func(my *Conn) error { return my.SetMultiStatements(true) },
+			[]byte{_COM_SET_OPTION, 0, 0}, eofPkt,
+		},
+		{
+			func(my *Conn) error { return my.SetMultiStatements(false) },
+			[]byte{_COM_SET_OPTION, 1, 0}, eofPkt,
+		},
+		{
+			func(my *Conn) error { return my.Shutdown(SHUTDOWN_DEFAULT) },
+			[]byte{_COM_SHUTDOWN, 0}, eofPkt,
+		},
+		{
+			func(my *Conn) error { return my.DumpDebugInfo() },
+			[]byte{_COM_DEBUG}, eofPkt,
+		},
+	}
+	for _, tt := range tests {
+		my := testConn(0, &out, tt.resp)
+		if err := tt.cmd(my); err != nil {
+			t.Fatal(err)
+		}
+		checkCmd(t, &out, tt.pkt)
+		if my.status != _SERVER_STATUS_AUTOCOMMIT {
+			t.Fatalf("bad status after command 0x%x: 0x%x", tt.pkt[0], my.status)
+		}
+	}
+	my := testConn(0, nil)
+	my.unreaded_reply = true
+	if err := my.Refresh(REFRESH_TABLES); err != mysql.ErrUnreadedReply {
+		t.Fatalf("Refresh with unreaded reply returned %v", err)
+	}
+}
+
+// Returns payloads of command packets written to out
+func cmdPkts(out *bytes.Buffer) (pkts []string) {
+	buf := out.Bytes()
+	for len(buf) >= 4 {
+		n := int(readU24(bytes.NewReader(buf))) + 4
+		pkts = append(pkts, string(buf[4:n]))
+		buf = buf[n:]
+	}
+	return
+}
+
+func TestResetSession(t *testing.T) {
+	prepOk := []byte{0, 9, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	for _, ver := range []string{"8.0.36", "5.6.10"} {
+		var out bytes.Buffer
+		my := testConn(0, &out, okPkt, nil, okPkt, nil, okPkt, nil, prepOk)
+		my.info.serv_ver = ver
+		my.info.scramble = make([]byte, 20)
+		my.user = "user"
+		my.dbname = "test"
+		my.collation = 33
+		my.Register("SET @a = 1")
+		stmt := &Stmt{my: my, id: 1, sql: "DO 1"}
+		my.stmt_map[1] = stmt
+
+		if err := my.ResetSession(); err != nil {
+			t.Fatal(ver, err)
+		}
+		pkts := cmdPkts(&out)
+		if len(pkts) != 4 {
+			t.Fatalf("%s: bad number of commands: %q", ver, pkts)
+		}
+		if ver == "8.0.36" {
+			if pkts[0] != "\x1f" {
+				t.Fatalf("%s: COM_RESET_CONNECTION expected: %q", ver, pkts[0])
+			}
+		} else if pkts[0][0] != _COM_CHANGE_USER ||
+			pkts[0][1:6] != "user\x00" || pkts[0][len(pkts[0])-5:] != "test\x00" {
+			t.Fatalf("%s: bad COM_CHANGE_USER: %q", ver, pkts[0])
+		}
+		exp := []string{
+			"\x03SET NAMES utf8 COLLATE utf8_general_ci",
+			"\x03SET @a = 1",
+			"\x16DO 1",
+		}
+		for ii, e := range exp {
+			if pkts[ii+1] != e {
+				t.Fatalf("%s: command %d: %q exp: %q", ver, ii+1, pkts[ii+1], e)
+			}
+		}
+		if stmt.id != 9 || my.stmt_map[9] != stmt || len(my.stmt_map) != 1 ||
+			!stmt.rebind {
+			t.Fatalf("%s: statement not reprepared: %+v", ver, stmt)
+		}
+	}
+}
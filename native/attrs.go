@@ -0,0 +1,70 @@
+package native
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	buildinfo "runtime/debug"
+	"sort"
+	"strconv"
+)
+
+// Returns version of mymysql module the program was built with or "" if it
+// is unknown (eg. GOPATH build or mymysql is the main module).
+func clientVersion() string {
+	bi, ok := buildinfo.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, m := range append(bi.Deps, &bi.Main) {
+		if m.Path == "github.com/ziutek/mymysql" && m.Version != "(devel)" {
+			return m.Version
+		}
+	}
+	return ""
+}
+
+// Returns default connection attributes
+func defaultAttrs() map[string]string {
+	attrs := map[string]string{
+		"_client_name": "mymysql",
+		"_os":          runtime.GOOS,
+		"_platform":    runtime.GOARCH,
+		"_pid":         strconv.Itoa(os.Getpid()),
+	}
+	if v := clientVersion(); v != "" {
+		attrs["_client_version"] = v
+	}
+	if len(os.Args) > 0 {
+		attrs["program_name"] = filepath.Base(os.Args[0])
+	}
+	return attrs
+}
+
+// Returns connection attributes that are sent to the server during connect
+// (see performance_schema.session_connect_attrs). Returned map may be
+// modified before Connect.
+func (my *Conn) Attrs() map[string]string {
+	return my.attrs
+}
+
+// Sets connection attribute. It is sent to the server during next connect.
+func (my *Conn) SetAttr(name, value string) {
+	my.attrs[name] = value
+}
+
+// Returns connection attributes encoded for handshake response packet
+func (my *Conn) encodedAttrs() []byte {
+	names := make([]string, 0, len(my.attrs))
+	for name := range my.attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for _, name := range names {
+		writeStr(&buf, name)
+		writeStr(&buf, my.attrs[name])
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,204 @@
+package native
+
+import (
+	"bytes"
+	"github.com/ziutek/mymysql/mysql"
+	"log"
+)
+
+// Returns true if COM_STMT_BULK_EXECUTE with results of every parameter set
+// (unit results) can be used.
+func (my *Conn) bulkExecute() bool {
+	caps := uint32(_MARIADB_CLIENT_STMT_BULK_OPERATIONS |
+		_MARIADB_CLIENT_BULK_UNIT_RESULTS)
+	return my.ext_caps&caps == caps && my.ServerInfo().SupportsBulkExecute()
+}
+
+// Binds row and encodes its parameters as a COM_STMT_BULK_EXECUTE unit:
+// indicator and value of every parameter. Types of not NULL parameters are
+// stored in types. Returns false if a type differs from the type of this
+// parameter in previous rows.
+func (stmt *Stmt) bulkRow(row []interface{}, types []uint16) ([]byte, bool) {
+	stmt.Bind(row...)
+	var buf bytes.Buffer
+	for ii, param := range stmt.params {
+		if param.Len() == 0 {
+			buf.WriteByte(_STMT_INDICATOR_NULL)
+			continue
+		}
+		if types[ii] == 0 {
+			types[ii] = param.typ
+		} else if types[ii] != param.typ {
+			return nil, false
+		}
+		buf.WriteByte(_STMT_INDICATOR_NONE)
+		writeValue(&buf, param)
+	}
+	return buf.Bytes(), true
+}
+
+func (stmt *Stmt) sendCmdBulkExec(types []uint16, units [][]byte) {
+	pkt_len := 1 + 4 + 2 + 2*len(types)
+	for _, unit := range units {
+		pkt_len += len(unit)
+	}
+	// Reset sequence number
+	stmt.my.seq = 0
+	// Packet sending
+	pw := stmt.my.newPktWriter(pkt_len)
+	writeByte(pw, _COM_STMT_BULK_EXECUTE)
+	writeU32(pw, stmt.id)
+	writeU16(pw, _STMT_BULK_FLAG_SEND_UNIT_RESULTS|_STMT_BULK_FLAG_SEND_TYPES)
+	for _, typ := range types {
+		writeU16(pw, typ)
+	}
+	for _, unit := range units {
+		write(pw, unit)
+	}
+	if stmt.my.Debug {
+		log.Printf("[%2d <-] Bulk exec command packet: len=%d, units=%d",
+			stmt.my.seq-1, pkt_len, len(units))
+	}
+}
+
+// Reads unit results (insert id and affected rows of every parameter set)
+// returned for COM_STMT_BULK_EXECUTE with n units.
+func (stmt *Stmt) getBulkResults(res *Result, n int) []mysql.BatchResult {
+	if res.StatusOnly() {
+		panic(mysql.ErrBadResult)
+	}
+	res.binary = true
+	rows, err := mysql.GetRows(res)
+	if err != nil {
+		panic(err)
+	}
+	if len(rows) != n || res.field_count != 2 {
+		panic(mysql.ErrBadResult)
+	}
+	results := make([]mysql.BatchResult, n)
+	for ii, row := range rows {
+		results[ii].InsertId = row.Uint64(0)
+		results[ii].AffectedRows = row.Uint64(1)
+	}
+	return results
+}
+
+// Executes units using COM_STMT_BULK_EXECUTE
+func (stmt *Stmt) bulkExec(types []uint16, units [][]byte) []mysql.BatchResult {
+	stmt.sendCmdBulkExec(types, units)
+	res, cause := stmt.getExecResponse()
+	if cause != nil {
+		if stmt.my.Debug {
+			log.Printf("Reprepare statement 0x%x: %s", stmt.id, cause)
+		}
+		stmt.reprepare(cause)
+		stmt.sendCmdBulkExec(types, units)
+		res = stmt.my.getResponse()
+	}
+	return stmt.getBulkResults(res, len(units))
+}
+
+// Executes rows using COM_STMT_BULK_EXECUTE commands that don't exceed the
+// maximum packet size. Returns false if rows can't be sent this way
+// (parameter types differ between rows).
+func (stmt *Stmt) execBulk(rows [][]interface{}, results *[]mysql.BatchResult) bool {
+	types := make([]uint16, stmt.param_count)
+	units := make([][]byte, len(rows))
+	for ii, row := range rows {
+		var ok bool
+		if units[ii], ok = stmt.bulkRow(row, types); !ok {
+			return false
+		}
+	}
+	// Next execution have to send parameter types
+	stmt.rebind = true
+	for ii, typ := range types {
+		if typ == 0 {
+			// Parameter is NULL in all rows
+			types[ii] = MYSQL_TYPE_NULL
+		}
+	}
+	hdr_len := 1 + 4 + 2 + 2*len(types)
+	for len(units) > 0 {
+		n, size := 1, hdr_len+len(units[0])
+		for n < len(units) && size+len(units[n]) <= stmt.my.max_pkt_size {
+			size += len(units[n])
+			n++
+		}
+		*results = append(*results, stmt.bulkExec(types, units[:n])...)
+		units = units[n:]
+	}
+	return true
+}
+
+// Executes rows using pipelined COM_STMT_EXECUTE commands. Errors returned
+// by the server are stored in results of rows.
+func (stmt *Stmt) execPipelined(rows [][]interface{}, results *[]mysql.BatchResult) error {
+	my := stmt.my
+	p := my.Pipeline().(*Pipeline)
+	run := func() error {
+		prs, err := p.Run()
+		for _, pr := range prs {
+			r := mysql.BatchResult{Err: pr.Err}
+			if pr.Err == nil {
+				r.AffectedRows = pr.Res.AffectedRows()
+				r.InsertId = pr.Res.InsertId()
+			}
+			*results = append(*results, r)
+		}
+		return err
+	}
+	for _, row := range rows {
+		if len(row) == 0 && stmt.param_count != 0 {
+			// Don't use parameters binded before
+			run()
+			return mysql.ErrBindCount
+		}
+		if err := p.Exec(stmt, row...); err != nil {
+			run()
+			return err
+		}
+		if p.buf.Len() >= my.max_pkt_size {
+			if err := run(); err != nil {
+				return err
+			}
+		}
+	}
+	return run()
+}
+
+// Executes the statement for every parameter set in rows. Returns affected
+// rows and insert id of every execution in order of rows.
+//
+// If the server supports MariaDB bulk operations, rows are sent in
+// COM_STMT_BULK_EXECUTE commands (as many rows in one command as fits in the
+// maximum packet size). An error returned by the server aborts such command
+// and is returned with results of previous commands. Bulk execution requires
+// the same types of not NULL values of a parameter in all rows.
+//
+// Otherwise (or if types differ) rows are executed using pipelined
+// COM_STMT_EXECUTE commands (see Pipeline). Errors returned by the server
+// for a row are stored in its result and don't stop the batch.
+//
+// After ExecBatch the statement is binded to the last row.
+func (stmt *Stmt) ExecBatch(rows [][]interface{}) (results []mysql.BatchResult, err error) {
+	defer catchError(&err)
+
+	my := stmt.my
+	if my.net_conn == nil {
+		return nil, mysql.ErrNotConn
+	}
+	if my.unreaded_reply {
+		return nil, mysql.ErrUnreadedReply
+	}
+	if len(rows) == 0 {
+		return
+	}
+	results = make([]mysql.BatchResult, 0, len(rows))
+	if stmt.param_count != 0 && my.bulkExecute() &&
+		stmt.execBulk(rows, &results) {
+		return
+	}
+	err = stmt.execPipelined(rows, &results)
+	return
+}
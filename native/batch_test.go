@@ -0,0 +1,138 @@
+package native
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/ziutek/mymysql/mysql"
+	"testing"
+)
+
+func TestInitExtCaps(t *testing.T) {
+	var pkt bytes.Buffer
+	pkt.WriteByte(10)
+	writeNTS(&pkt, "5.5.5-10.6.5-MariaDB")
+	writeU32(&pkt, 5)
+	pkt.WriteString("12345678\x00")
+	writeU16(&pkt, uint16(_CLIENT_PROTOCOL_41|_CLIENT_SECURE_CONN))
+	pkt.WriteByte(33)
+	writeU16(&pkt, _SERVER_STATUS_AUTOCOMMIT)
+	writeU16(&pkt, 0)
+	pkt.WriteByte(21)
+	pkt.Write(make([]byte, 6))
+	writeU32(&pkt, _MARIADB_CLIENT_PROGRESS|_MARIADB_CLIENT_STMT_BULK_OPERATIONS|
+		_MARIADB_CLIENT_BULK_UNIT_RESULTS)
+	pkt.WriteString("abcdefghijkl\x00mysql_native_password\x00")
+
+	var buf, out bytes.Buffer
+	writeU24(&buf, uint32(pkt.Len()))
+	buf.WriteByte(0) // Handshake is the first packet
+	buf.Write(pkt.Bytes())
+	my := testConn(0, &out)
+	my.rd = bufio.NewReader(&buf)
+	my.init()
+	my.auth()
+	my.wr.Flush()
+	exp := uint32(_MARIADB_CLIENT_STMT_BULK_OPERATIONS |
+		_MARIADB_CLIENT_BULK_UNIT_RESULTS)
+	if my.ext_caps != exp {
+		t.Fatalf("ext caps: 0x%x exp: 0x%x", my.ext_caps, exp)
+	}
+	if ext := readU32(bytes.NewReader(out.Bytes()[4+28:])); ext != exp {
+		t.Fatalf("sent ext caps: 0x%x exp: 0x%x", ext, exp)
+	}
+	if !my.bulkExecute() {
+		t.Fatal("bulk execution not used")
+	}
+}
+
+// Returns binary row with unit result
+func unitPkt(id, affected uint64) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0, 0})
+	writeU64(&buf, id)
+	writeU64(&buf, affected)
+	return buf.Bytes()
+}
+
+func TestExecBatchBulk(t *testing.T) {
+	var out bytes.Buffer
+	my := testConn(
+		0, &out,
+		[]byte{2},
+		fieldPkt("Id", MYSQL_TYPE_LONGLONG, 63),
+		fieldPkt("Affected_rows", MYSQL_TYPE_LONGLONG, 63),
+		eofPkt, unitPkt(7, 1), unitPkt(0, 1), eofPkt,
+	)
+	my.info.serv_ver = "5.5.5-10.6.5-MariaDB"
+	my.ext_caps = _MARIADB_CLIENT_STMT_BULK_OPERATIONS |
+		_MARIADB_CLIENT_BULK_UNIT_RESULTS
+	stmt := &Stmt{
+		my:          my,
+		id:          5,
+		params:      make([]*paramValue, 2),
+		param_count: 2,
+	}
+	results, err := stmt.ExecBatch([][]interface{}{
+		{int64(1), "a"},
+		{nil, "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []mysql.BatchResult{{AffectedRows: 1, InsertId: 7}, {AffectedRows: 1}}
+	if len(results) != len(exp) || results[0] != exp[0] || results[1] != exp[1] {
+		t.Fatalf("results: %+v", results)
+	}
+	checkCmds(t, &out,
+		"\xfa\x05\x00\x00\x00\xc0\x00\x08\x00\xfe\x00"+
+			"\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x01a"+
+			"\x01\x00\x01b",
+	)
+	if !stmt.rebind || my.unreaded_reply {
+		t.Fatalf("bad state after ExecBatch: rebind=%t unreaded_reply=%t",
+			stmt.rebind, my.unreaded_reply)
+	}
+}
+
+func TestExecBatchPipelined(t *testing.T) {
+	var out bytes.Buffer
+	my := testConn(
+		0, &out,
+		[]byte{0, 1, 3, 2, 0, 0, 0}, nil,
+		errPkt, nil,
+		[]byte{0, 2, 4, 2, 0, 0, 0},
+	)
+	// Bulk execution isn't used for different types of parameter values
+	my.info.serv_ver = "5.5.5-10.6.5-MariaDB"
+	my.ext_caps = _MARIADB_CLIENT_STMT_BULK_OPERATIONS |
+		_MARIADB_CLIENT_BULK_UNIT_RESULTS
+	stmt := &Stmt{
+		my:          my,
+		id:          5,
+		params:      make([]*paramValue, 1),
+		param_count: 1,
+	}
+	results, err := stmt.ExecBatch([][]interface{}{
+		{int8(1)}, {int8(2)}, {"x"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 ||
+		results[0] != (mysql.BatchResult{AffectedRows: 1, InsertId: 3}) ||
+		results[2] != (mysql.BatchResult{AffectedRows: 2, InsertId: 4}) {
+		t.Fatalf("results: %+v", results)
+	}
+	if e, ok := results[1].Err.(*mysql.Error); !ok || e.Code != mysql.ER_NO_SUCH_THREAD {
+		t.Fatalf("result 1: bad error: %v", results[1].Err)
+	}
+	checkCmds(t, &out,
+		"\x17\x05\x00\x00\x00\x00\x01\x00\x00\x00\x00\x01\x01\x00\x01",
+		"\x17\x05\x00\x00\x00\x00\x01\x00\x00\x00\x00\x01\x01\x00\x02",
+		"\x17\x05\x00\x00\x00\x00\x01\x00\x00\x00\x00\x01\xfe\x00\x01x",
+	)
+
+	if _, err = stmt.ExecBatch([][]interface{}{{}}); err != mysql.ErrBindCount {
+		t.Fatalf("ExecBatch returned %v", err)
+	}
+}
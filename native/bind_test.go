@@ -2,9 +2,12 @@ package native
 
 import (
 	"bytes"
+	"encoding/json"
 	"github.com/ziutek/mymysql/mysql"
+	"github.com/ziutek/mymysql/mysql/geo"
 	"math"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -16,7 +19,7 @@ var (
 	dateT  = time.Date(2010, 12, 30, 17, 21, 01, 0, time.Local)
 	tstamp = mysql.Timestamp{dateT.Add(1e9)}
 	date   = mysql.Date{Year: 2011, Month: 2, Day: 3}
-	tim    = -time.Duration((5*24*3600+4*3600+3*60+2)*1e9 + 1)
+	tim    = -time.Duration((5*24*3600+4*3600+3*60+2)*1e9 + 1e3)
 	bol    = true
 
 	pBytes  *[]byte
@@ -301,7 +304,7 @@ func TestWrite(t *testing.T) {
 func TestEscapeString(t *testing.T) {
 	txt := " \000 \n \r \\ ' \" \032 "
 	exp := ` \0 \n \r \\ \' \" \Z `
-	out := escapeString(txt)
+	out := escapeString(txt, nil)
 	if out != exp {
 		t.Fatalf("escapeString: ret='%s' exp='%s'", out, exp)
 	}
@@ -309,8 +312,119 @@ func TestEscapeString(t *testing.T) {
 func TestEscapeQuotes(t *testing.T) {
 	txt := " '' '' ' ' ' "
 	exp := ` '''' '''' '' '' '' `
-	out := escapeQuotes(txt)
+	out := escapeQuotes(txt, nil)
 	if out != exp {
 		t.Fatalf("escapeString: ret='%s' exp='%s'", out, exp)
 	}
 }
+
+type jsonDoc struct {
+	A int
+}
+
+func (d *jsonDoc) MarshalJSON() ([]byte, error) {
+	return []byte(`{"a":` + strconv.Itoa(d.A) + `}`), nil
+}
+
+func TestBindJson(t *testing.T) {
+	doc := jsonDoc{7}
+	var pdoc *jsonDoc
+	tests := []struct {
+		val interface{}
+		exp []byte
+	}{
+		{json.RawMessage(`[1]`), []byte("\x03[1]")},
+		{&doc, []byte("\x07{\"a\":7}")},
+		{doc, []byte("\x07{\"a\":7}")},
+		{pdoc, nil},
+	}
+	buf := new(bytes.Buffer)
+	for _, test := range tests {
+		buf.Reset()
+		val := bindValue(makeAddressable(reflect.ValueOf(test.val)))
+		l := val.Len()
+		writeValue(buf, val)
+		if !bytes.Equal(buf.Bytes(), test.exp) || l != len(test.exp) {
+			t.Errorf("%T - exp: %q res: %q len: %d", test.val, test.exp,
+				buf.Bytes(), l)
+		}
+	}
+	// Value binded by pointer is encoded during write
+	val := bindValue(makeAddressable(reflect.ValueOf(&doc)))
+	doc.A = 10
+	buf.Reset()
+	writeValue(buf, val)
+	if buf.String() != "\x08{\"a\":10}" {
+		t.Errorf("Bad value binded by pointer: %q", buf.Bytes())
+	}
+}
+
+func TestBindGeometry(t *testing.T) {
+	pt := geo.Point{X: 1, Y: 2}
+	exp := append([]byte{25, 0, 0, 0, 0}, geo.EncodeWKB(pt)...)
+	tests := []struct {
+		val interface{}
+		exp []byte
+	}{
+		{pt, exp},
+		{&pt, exp},
+		{geo.Value{Geometry: pt}, exp},
+		{geo.Value{}, nil},
+		{(*geo.Point)(nil), nil},
+	}
+	buf := new(bytes.Buffer)
+	for _, test := range tests {
+		buf.Reset()
+		val := bindValue(makeAddressable(reflect.ValueOf(test.val)))
+		l := val.Len()
+		writeValue(buf, val)
+		if !bytes.Equal(buf.Bytes(), test.exp) || l != len(test.exp) ||
+			val.typ != MYSQL_TYPE_BLOB {
+			t.Errorf("%T - exp: %q res: %q len: %d", test.val, test.exp,
+				buf.Bytes(), l)
+		}
+	}
+}
+
+func TestTimeCodecs(t *testing.T) {
+	tt := time.Date(2014, 3, 4, 5, 6, 7, 123456789, time.Local)
+	if r, ok := readTime(bytes.NewReader(EncodeTime(tt))).(time.Time); !ok ||
+		!r.Equal(tt.Truncate(1e3)) {
+		t.Fatalf("Bad time: %v", r)
+	}
+	if r := readTime(bytes.NewReader(EncodeTime(mysql.ZeroTime))); r != mysql.ZeroTime {
+		t.Fatalf("Bad zero time: %v", r)
+	}
+	// time.Time zero is a real 0001-01-01 00:00:00 UTC, not MySQL zero
+	r := readTime(bytes.NewReader(EncodeTime(time.Time{})))
+	if r, ok := r.(time.Time); !ok || r.Year() != 1 || mysql.IsZeroTime(r) {
+		t.Fatalf("Bad 0001-01-01: %v", r)
+	}
+	zid := []byte{7, 0xde, 0x07, 0, 0, 1, 2, 3} // 2014-00-00 01:02:03
+	if r, ok := readTime(bytes.NewReader(zid)).([]byte); !ok ||
+		string(r) != "2014-00-00 01:02:03" {
+		t.Fatalf("Bad zero-in-date time: %v", r)
+	}
+	zid = []byte{4, 0xde, 0x07, 3, 0} // 2014-03-00
+	if _, err := (mysql.Row{readDate(bytes.NewReader(zid))}).TimeErr(
+		0, time.Local,
+	); err != mysql.ErrZeroInDate {
+		t.Fatalf("Bad zero-in-date date error: %v", err)
+	}
+	if d := readDate(bytes.NewReader([]byte{0})); !d.IsZero() {
+		t.Fatalf("Bad zero date: %v", d)
+	}
+	durations := []time.Duration{
+		0, time.Second, -time.Microsecond, 838*time.Hour + 59*time.Second,
+		-(49*time.Hour + 1500*time.Microsecond),
+	}
+	for _, d := range durations {
+		buf := EncodeDuration(d)
+		if len(buf) != lenDuration(d) {
+			t.Fatalf("Bad length of %v: %d", d, len(buf))
+		}
+		if r := readDuration(bytes.NewReader(buf)); r != d {
+			t.Fatalf("Bad duration: %v != %v", r, d)
+		}
+	}
+}
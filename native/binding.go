@@ -1,7 +1,9 @@
 package native
 
 import (
+	"encoding/json"
 	"github.com/ziutek/mymysql/mysql"
+	"github.com/ziutek/mymysql/mysql/geo"
 	"reflect"
 	"time"
 )
@@ -13,14 +15,91 @@ var (
 	durationType  = reflect.TypeOf(time.Duration(0))
 	blobType      = reflect.TypeOf(mysql.Blob{})
 	rawType       = reflect.TypeOf(mysql.Raw{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	geometryType  = reflect.TypeOf((*geo.Geometry)(nil)).Elem()
+	geoValueType  = reflect.TypeOf(geo.Value{})
 )
 
+// True if typ is geo.Value or implements geo.Geometry
+func isGeometry(typ reflect.Type) bool {
+	return typ == geoValueType || typ.Implements(geometryType)
+}
+
+// Returns function that encodes val in MySQL internal geometry format or nil
+// if val isn't a geometry.
+func geoEncoder(val reflect.Value) func() []byte {
+	typ := val.Type()
+	ptr := typ.Kind() == reflect.Ptr
+	if ptr {
+		typ = typ.Elem()
+	}
+	if !isGeometry(typ) {
+		return nil
+	}
+	return func() []byte {
+		v := val
+		if ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		switch g := v.Interface().(type) {
+		case geo.Value:
+			if g.Geometry == nil {
+				return nil
+			}
+			return geo.Encode(g.SRID, g.Geometry)
+		case geo.Geometry:
+			return geo.Encode(0, g)
+		}
+		return nil
+	}
+}
+
+// True if typ or pointer to typ implements json.Marshaler
+func isJsonMarshaler(typ reflect.Type) bool {
+	return typ.Implements(marshalerType) ||
+		reflect.PtrTo(typ).Implements(marshalerType)
+}
+
+// Returns function that encodes val using its MarshalJSON method or nil if
+// val doesn't implement json.Marshaler. val should be an addressable value.
+func jsonMarshaler(val reflect.Value) func() []byte {
+	typ := val.Type()
+	ptr := typ.Kind() == reflect.Ptr
+	if ptr {
+		typ = typ.Elem()
+	}
+	if !isJsonMarshaler(typ) {
+		return nil
+	}
+	return func() []byte {
+		v := val
+		if ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		if !typ.Implements(marshalerType) {
+			v = v.Addr()
+		}
+		buf, err := v.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			panic(err)
+		}
+		return buf
+	}
+}
+
 // val should be an addressable value
 func bindValue(val reflect.Value) (out *paramValue) {
 	if !val.IsValid() {
 		return &paramValue{typ: MYSQL_TYPE_NULL}
 	}
 	typ := val.Type()
+	orig := val
 	out = new(paramValue)
 	if typ.Kind() == reflect.Ptr {
 		// We have addressable pointer
@@ -147,5 +226,16 @@ func bindValue(val reflect.Value) (out *paramValue) {
 		out.length = -1
 		return
 	}
+	if out.marshal = geoEncoder(orig); out.marshal != nil {
+		out.typ = MYSQL_TYPE_BLOB
+		out.length = -1
+		return
+	}
+	if out.marshal = jsonMarshaler(orig); out.marshal != nil {
+		// JSON document is sent as text
+		out.typ = MYSQL_TYPE_STRING
+		out.length = -1
+		return
+	}
 	panic(mysql.ErrBindUnkType)
 }
@@ -0,0 +1,395 @@
+package native
+
+import (
+	"github.com/ziutek/mymysql/mysql"
+	"hash/crc32"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+)
+
+const _BINLOG_EVENT_HEADER_LEN = 19
+
+// Binlog event received from the server
+type BinlogEvent struct {
+	Timestamp uint32
+	Type      byte
+	ServerId  uint32
+	LogPos    uint32 // Position of the next event in the binlog file
+	Flags     uint16
+	Data      []byte // Event body (without header and checksum)
+}
+
+// Returns sid:gno of GTID_EVENT.
+func (ev *BinlogEvent) Gtid() (sid mysql.Uuid, gno int64, err error) {
+	if ev.Type != GTID_EVENT || len(ev.Data) < 1+16+8 {
+		return sid, 0, mysql.ErrBinlogEvent
+	}
+	copy(sid[:], ev.Data[1:17])
+	gno = int64(DecodeU64(ev.Data[17:25]))
+	return
+}
+
+// Returns default database and SQL text of QUERY_EVENT.
+func (ev *BinlogEvent) Query() (db, sql string, err error) {
+	// thread_id, exec_time, db_len, error_code, status_vars_len
+	const post_hdr_len = 4 + 4 + 1 + 2 + 2
+	if ev.Type != QUERY_EVENT || len(ev.Data) < post_hdr_len {
+		return "", "", mysql.ErrBinlogEvent
+	}
+	db_len := int(ev.Data[8])
+	vars_len := int(DecodeU16(ev.Data[11:13]))
+	buf := ev.Data[post_hdr_len:]
+	if len(buf) < vars_len+db_len+1 {
+		return "", "", mysql.ErrBinlogEvent
+	}
+	buf = buf[vars_len:]
+	db = string(buf[:db_len])
+	sql = string(buf[db_len+1:])
+	return
+}
+
+// Returns the next binlog file name and position from ROTATE_EVENT.
+func (ev *BinlogEvent) Rotate() (file string, pos uint64, err error) {
+	if ev.Type != ROTATE_EVENT || len(ev.Data) < 8 {
+		return "", 0, mysql.ErrBinlogEvent
+	}
+	return string(ev.Data[8:]), DecodeU64(ev.Data[:8]), nil
+}
+
+// Position in the binlog from which a consumer can resume streaming.
+type BinlogPos struct {
+	File  string
+	Pos   uint32
+	Gtids *mysql.GtidSet // Executed GTIDs (nil if GTIDs aren't used)
+}
+
+// Stream of binlog events. Use Conn.BinlogDump, Conn.BinlogDumpGtid or
+// Conn.ResumeBinlogDump to create it.
+type BinlogStream struct {
+	my *Conn
+
+	checksum bool   // Events are followed by CRC32 checksum
+	post_hdr []byte // Post-header lengths of event types (from FDE)
+	end      bool   // Server finished sending events
+
+	tables map[uint64]*BinlogTable // Tables from TABLE_MAP_EVENTs
+
+	file     string // Current binlog file
+	next_pos uint32 // Position after last received event
+
+	// State of the current transaction
+	in_trx       bool
+	gtid_pending bool
+	gtid_sid     mysql.Uuid
+	gtid_gno     int64
+
+	pos BinlogPos // Last transaction boundary
+}
+
+// Checks whether the server sends checksums and declares that we can handle
+// them.
+func (my *Conn) binlogChecksum() (bool, error) {
+	row, _, err := my.QueryFirst("SELECT @@global.binlog_checksum")
+	if err != nil {
+		if e, ok := err.(*mysql.Error); ok &&
+			e.Code == mysql.ER_UNKNOWN_SYSTEM_VARIABLE {
+			// MySQL < 5.6.2 doesn't support checksums
+			return false, nil
+		}
+		return false, err
+	}
+	_, _, err = my.Query(
+		"SET @master_binlog_checksum = @@global.binlog_checksum",
+	)
+	return row.Str(0) == "CRC32", err
+}
+
+func (my *Conn) binlogDump(server_id uint32, non_block bool, file string, pos uint32, gtids *mysql.GtidSet) (*BinlogStream, error) {
+	if my.net_conn == nil {
+		return nil, mysql.ErrNotConn
+	}
+	if my.unreaded_reply {
+		return nil, mysql.ErrUnreadedReply
+	}
+	if gtids != nil && !my.ServerInfo().SupportsBinlogDumpGtid() {
+		return nil, mysql.ErrNotSupported
+	}
+	if pos < 4 {
+		// Skip binlog magic number
+		pos = 4
+	}
+
+	checksum, err := my.binlogChecksum()
+	if err != nil {
+		return nil, err
+	}
+	bs := &BinlogStream{
+		my:       my,
+		checksum: checksum,
+		file:     file,
+		next_pos: pos,
+		pos:      BinlogPos{File: file, Pos: pos},
+	}
+	flags := uint16(0)
+	if non_block {
+		flags |= _BINLOG_DUMP_NON_BLOCK
+	}
+	if gtids != nil {
+		bs.pos.Gtids = gtids.Clone()
+	}
+	if err = bs.sendDump(server_id, flags, gtids); err != nil {
+		return nil, err
+	}
+	return bs, nil
+}
+
+func (bs *BinlogStream) sendDump(server_id uint32, flags uint16, gtids *mysql.GtidSet) (err error) {
+	defer catchError(&err)
+
+	my := bs.my
+	if gtids == nil {
+		my.sendCmd(_COM_BINLOG_DUMP, bs.next_pos, flags, server_id, bs.file)
+	} else {
+		my.sendCmd(
+			_COM_BINLOG_DUMP_GTID,
+			flags|_BINLOG_THROUGH_GTID, server_id, bs.file,
+			uint64(bs.next_pos), gtids.Encode(),
+		)
+	}
+	// Until the end of stream the connection can't be used for other commands
+	my.unreaded_reply = true
+	return
+}
+
+// Starts streaming of binlog events from specified file and position.
+// server_id must be unique among all replicas of the server. If non_block
+// is true the server finishes the stream (GetEvent returns io.EOF) when there
+// is no more events, otherwise it waits for new events.
+//
+// The connection can't be used for other commands until the end of stream.
+func (my *Conn) BinlogDump(server_id uint32, non_block bool, file string, pos uint32) (*BinlogStream, error) {
+	return my.binlogDump(server_id, non_block, file, pos, nil)
+}
+
+// Starts streaming of binlog events (COM_BINLOG_DUMP_GTID). The server sends
+// all transactions not contained in executed GTID set. See BinlogDump for
+// description of other parameters.
+func (my *Conn) BinlogDumpGtid(server_id uint32, non_block bool, executed *mysql.GtidSet) (*BinlogStream, error) {
+	if executed == nil {
+		executed = mysql.NewGtidSet()
+	}
+	return my.binlogDump(server_id, non_block, "", 4, executed)
+}
+
+// Resumes streaming from position returned by BinlogStream.Checkpoint. If the
+// checkpoint contains GTID set COM_BINLOG_DUMP_GTID is used so the position
+// is valid after failover to other server. Otherwise it uses binlog file and
+// position.
+func (my *Conn) ResumeBinlogDump(server_id uint32, non_block bool, cp BinlogPos) (*BinlogStream, error) {
+	if cp.Gtids != nil {
+		return my.BinlogDumpGtid(server_id, non_block, cp.Gtids)
+	}
+	return my.BinlogDump(server_id, non_block, cp.File, cp.Pos)
+}
+
+// Reads next event from the server. Returns io.EOF if the server finished
+// the stream (possible only in non blocking mode).
+func (bs *BinlogStream) GetEvent() (ev *BinlogEvent, err error) {
+	defer catchError(&err)
+
+	my := bs.my
+	if bs.end {
+		return nil, io.EOF
+	}
+	if my.net_conn == nil {
+		return nil, mysql.ErrNotConn
+	}
+
+	pr := my.newPktReader()
+	switch readByte(pr) {
+	case 0:
+		// Binlog event
+	case 254:
+		// EOF packet - no more events
+		my.getEofPacket(pr)
+		bs.end = true
+		my.unreaded_reply = false
+		return nil, io.EOF
+	case 255:
+		bs.end = true
+		my.unreaded_reply = false
+		my.getErrorPacket(pr)
+	default:
+		panic(mysql.ErrUnkResultPkt)
+	}
+	ev = bs.parseEvent(pr.readAll())
+	bs.track(ev)
+
+	if my.Debug {
+		log.Printf("[%2d ->] Binlog event packet: Type=0x%x LogPos=%d",
+			my.seq-1, ev.Type, ev.LogPos,
+		)
+	}
+	return
+}
+
+func (bs *BinlogStream) parseEvent(buf []byte) *BinlogEvent {
+	if len(buf) < _BINLOG_EVENT_HEADER_LEN ||
+		DecodeU32(buf[9:13]) != uint32(len(buf)) {
+		panic(mysql.ErrBinlogEvent)
+	}
+	ev := &BinlogEvent{
+		Timestamp: DecodeU32(buf[0:4]),
+		Type:      buf[4],
+		ServerId:  DecodeU32(buf[5:9]),
+		LogPos:    DecodeU32(buf[13:17]),
+		Flags:     DecodeU16(buf[17:19]),
+	}
+	if ev.Type == FORMAT_DESCRIPTION_EVENT {
+		// Every binlog file starts with FDE that describes the checksum
+		// algorithm used for the rest of the file.
+		bs.formatDescription(buf[_BINLOG_EVENT_HEADER_LEN:])
+	}
+	if bs.checksum {
+		n := len(buf) - 4
+		if n < _BINLOG_EVENT_HEADER_LEN {
+			panic(mysql.ErrBinlogEvent)
+		}
+		if crc32.ChecksumIEEE(buf[:n]) != DecodeU32(buf[n:]) {
+			panic(mysql.ErrBinlogChecksum)
+		}
+		buf = buf[:n]
+	}
+	ev.Data = buf[_BINLOG_EVENT_HEADER_LEN:]
+	return ev
+}
+
+// Reads checksum algorithm and post-header lengths from FDE. The body of FDE
+// is: binlog_version(2), server_version(50), create_timestamp(4),
+// header_length(1), post_header_lengths(n) and since MySQL 5.6.1
+// checksum_alg(1), checksum(4).
+func (bs *BinlogStream) formatDescription(body []byte) {
+	if len(body) < 2+50+4+1 {
+		panic(mysql.ErrBinlogEvent)
+	}
+	ver := string(body[2:52])
+	if n := strings.IndexByte(ver, 0); n != -1 {
+		ver = ver[:n]
+	}
+	bs.post_hdr = body[57:]
+	bs.checksum = false
+	v := splitVersion(ver)
+	if v[0] > 5 || v[0] == 5 && (v[1] > 6 || v[1] == 6 && v[2] >= 1) {
+		if len(bs.post_hdr) < 5 {
+			panic(mysql.ErrBinlogEvent)
+		}
+		bs.checksum = body[len(body)-5] == 1 // BINLOG_CHECKSUM_ALG_CRC32
+		bs.post_hdr = bs.post_hdr[:len(bs.post_hdr)-5]
+	}
+	bs.post_hdr = append([]byte(nil), bs.post_hdr...)
+}
+
+// Returns major, minor and patch numbers of the version string.
+func splitVersion(ver string) (v [3]int) {
+	for ii, s := range strings.SplitN(ver, ".", 3) {
+		n := 0
+		for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+		v[ii], _ = strconv.Atoi(s[:n])
+	}
+	return
+}
+
+// Tracks transaction boundaries for checkpointing.
+func (bs *BinlogStream) track(ev *BinlogEvent) {
+	if ev.LogPos != 0 {
+		bs.next_pos = ev.LogPos
+	}
+	switch ev.Type {
+	case ROTATE_EVENT:
+		file, pos, err := ev.Rotate()
+		if err != nil {
+			panic(err)
+		}
+		bs.file = file
+		bs.next_pos = uint32(pos)
+		if !bs.in_trx && !bs.gtid_pending {
+			bs.pos.File = bs.file
+			bs.pos.Pos = bs.next_pos
+		}
+
+	case GTID_EVENT:
+		sid, gno, err := ev.Gtid()
+		if err != nil {
+			panic(err)
+		}
+		bs.gtid_sid = sid
+		bs.gtid_gno = gno
+		bs.gtid_pending = true
+
+	case QUERY_EVENT:
+		_, sql, err := ev.Query()
+		if err != nil {
+			panic(err)
+		}
+		switch {
+		case sql == "BEGIN":
+			bs.in_trx = true
+		case !bs.in_trx || sql == "COMMIT" || sql == "ROLLBACK":
+			// DDL or end of non-transactional "transaction"
+			bs.commit()
+		}
+
+	case XID_EVENT:
+		bs.commit()
+
+	case TABLE_MAP_EVENT:
+		tab := bs.parseTableMap(ev)
+		if bs.tables == nil {
+			bs.tables = make(map[uint64]*BinlogTable)
+		}
+		bs.tables[tab.Id] = tab
+	}
+}
+
+func (bs *BinlogStream) commit() {
+	if bs.gtid_pending && bs.pos.Gtids != nil {
+		bs.pos.Gtids.Add(bs.gtid_sid, bs.gtid_gno)
+	}
+	bs.gtid_pending = false
+	bs.in_trx = false
+	bs.pos.File = bs.file
+	bs.pos.Pos = bs.next_pos
+}
+
+// Returns position just after the last completely received transaction.
+// Pass it to Conn.ResumeBinlogDump after reconnect to continue streaming
+// without duplicate or missing transactions.
+func (bs *BinlogStream) Checkpoint() BinlogPos {
+	cp := bs.pos
+	if cp.Gtids != nil {
+		cp.Gtids = cp.Gtids.Clone()
+	}
+	return cp
+}
+
+// Closes the stream. The MySQL protocol doesn't allow to stop the binlog dump
+// so if the server hasn't finished the stream the connection is closed too
+// (use Reconnect to reopen it).
+func (bs *BinlogStream) Close() (err error) {
+	my := bs.my
+	if bs.end {
+		return
+	}
+	bs.end = true
+	if my.net_conn == nil {
+		return mysql.ErrNotConn
+	}
+	my.unreaded_reply = false
+	err = my.net_conn.Close()
+	my.net_conn = nil // Mark that we disconnect
+	return
+}
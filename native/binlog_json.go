@@ -0,0 +1,205 @@
+package native
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/ziutek/mymysql/mysql"
+	"math"
+	"strconv"
+)
+
+// Types of values in MySQL binary JSON format (see MySQL json_binary.h)
+const (
+	_JSONB_SMALL_OBJECT = 0x00
+	_JSONB_LARGE_OBJECT = 0x01
+	_JSONB_SMALL_ARRAY  = 0x02
+	_JSONB_LARGE_ARRAY  = 0x03
+	_JSONB_LITERAL      = 0x04
+	_JSONB_INT16        = 0x05
+	_JSONB_UINT16       = 0x06
+	_JSONB_INT32        = 0x07
+	_JSONB_UINT32       = 0x08
+	_JSONB_INT64        = 0x09
+	_JSONB_UINT64       = 0x0a
+	_JSONB_DOUBLE       = 0x0b
+	_JSONB_STRING       = 0x0c
+	_JSONB_OPAQUE       = 0x0f
+)
+
+// Converts JSON document in MySQL binary format (used in binlog) to its
+// text representation.
+func decodeJsonBin(data []byte) []byte {
+	if len(data) == 0 {
+		// Empty value is used for JSON null
+		return []byte("null")
+	}
+	var buf bytes.Buffer
+	jsonValue(&buf, data[0], data[1:])
+	return buf.Bytes()
+}
+
+// Returns data[off:off+n] or panics if data is too short
+func jsonSlice(data []byte, off, n int) []byte {
+	if off < 0 || n < 0 || off+n > len(data) {
+		panic(mysql.ErrBinlogEvent)
+	}
+	return data[off : off+n]
+}
+
+// Reads variable length integer (7 bits per byte, LSB first)
+func jsonVarLen(data []byte) (n, size int) {
+	for shift := uint(0); size < 5; shift += 7 {
+		b := jsonSlice(data, size, 1)[0]
+		size++
+		n |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return
+		}
+	}
+	panic(mysql.ErrBinlogEvent)
+}
+
+func jsonValue(buf *bytes.Buffer, typ byte, data []byte) {
+	switch typ {
+	case _JSONB_SMALL_OBJECT, _JSONB_LARGE_OBJECT:
+		jsonContainer(buf, data, typ == _JSONB_LARGE_OBJECT, true)
+
+	case _JSONB_SMALL_ARRAY, _JSONB_LARGE_ARRAY:
+		jsonContainer(buf, data, typ == _JSONB_LARGE_ARRAY, false)
+
+	case _JSONB_LITERAL:
+		switch jsonSlice(data, 0, 1)[0] {
+		case 0:
+			buf.WriteString("null")
+		case 1:
+			buf.WriteString("true")
+		case 2:
+			buf.WriteString("false")
+		default:
+			panic(mysql.ErrBinlogEvent)
+		}
+
+	case _JSONB_INT16:
+		fmt.Fprint(buf, int16(DecodeU16(jsonSlice(data, 0, 2))))
+
+	case _JSONB_UINT16:
+		fmt.Fprint(buf, DecodeU16(jsonSlice(data, 0, 2)))
+
+	case _JSONB_INT32:
+		fmt.Fprint(buf, int32(DecodeU32(jsonSlice(data, 0, 4))))
+
+	case _JSONB_UINT32:
+		fmt.Fprint(buf, DecodeU32(jsonSlice(data, 0, 4)))
+
+	case _JSONB_INT64:
+		fmt.Fprint(buf, int64(DecodeU64(jsonSlice(data, 0, 8))))
+
+	case _JSONB_UINT64:
+		fmt.Fprint(buf, DecodeU64(jsonSlice(data, 0, 8)))
+
+	case _JSONB_DOUBLE:
+		f := math.Float64frombits(DecodeU64(jsonSlice(data, 0, 8)))
+		buf.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+
+	case _JSONB_STRING:
+		n, size := jsonVarLen(data)
+		jsonString(buf, string(jsonSlice(data, size, n)))
+
+	case _JSONB_OPAQUE:
+		jsonOpaque(buf, jsonSlice(data, 0, 1)[0], data[1:])
+
+	default:
+		panic(mysql.ErrBinlogEvent)
+	}
+}
+
+func jsonString(buf *bytes.Buffer, s string) {
+	js, _ := json.Marshal(s)
+	buf.Write(js)
+}
+
+// Decodes object or array. Layout: element_count, size, key entries
+// (objects only), value entries, keys, values. Offsets and counts are
+// 2 bytes long in small and 4 bytes long in large containers.
+func jsonContainer(buf *bytes.Buffer, data []byte, large, object bool) {
+	osize := 2
+	if large {
+		osize = 4
+	}
+	offset := func(off int) int {
+		return int(DecodeU64(jsonSlice(data, off, osize)))
+	}
+	count := offset(0)
+	hdr := 2 * osize
+	val_entry := 1 + osize
+	if object {
+		buf.WriteByte('{')
+	} else {
+		buf.WriteByte('[')
+	}
+	for ii := 0; ii < count; ii++ {
+		if ii > 0 {
+			buf.WriteByte(',')
+		}
+		ve := hdr + ii*val_entry
+		if object {
+			ke := hdr + ii*(osize+2)
+			key_len := int(DecodeU16(jsonSlice(data, ke+osize, 2)))
+			jsonString(buf, string(jsonSlice(data, offset(ke), key_len)))
+			buf.WriteByte(':')
+			ve = hdr + count*(osize+2) + ii*val_entry
+		}
+		typ := jsonSlice(data, ve, 1)[0]
+		switch {
+		case typ == _JSONB_LITERAL || typ == _JSONB_INT16 ||
+			typ == _JSONB_UINT16 ||
+			large && (typ == _JSONB_INT32 || typ == _JSONB_UINT32):
+			// Value is inlined in value entry
+			jsonValue(buf, typ, jsonSlice(data, ve+1, osize))
+		default:
+			off := offset(ve + 1)
+			jsonValue(buf, typ, jsonSlice(data, off, len(data)-off))
+		}
+	}
+	if object {
+		buf.WriteByte('}')
+	} else {
+		buf.WriteByte(']')
+	}
+}
+
+// Decodes opaque value: MySQL specific type stored in JSON document.
+func jsonOpaque(buf *bytes.Buffer, typ byte, data []byte) {
+	n, size := jsonVarLen(data)
+	data = jsonSlice(data, size, n)
+	switch typ {
+	case MYSQL_TYPE_NEWDECIMAL:
+		if len(data) < 2 {
+			panic(mysql.ErrBinlogEvent)
+		}
+		buf.WriteString(decodeDecimal(data[2:], int(data[0]), int(data[1])))
+		return
+
+	case MYSQL_TYPE_DATE, MYSQL_TYPE_DATETIME, MYSQL_TYPE_TIMESTAMP:
+		y, mon, d, h, m, s, us := unpackDatetime(
+			int64(DecodeU64(jsonSlice(data, 0, 8))),
+		)
+		str := fmt.Sprintf("%04d-%02d-%02d", y, mon, d)
+		if typ != MYSQL_TYPE_DATE {
+			str += fmt.Sprintf(" %02d:%02d:%02d.%06d", h, m, s, us)
+		}
+		jsonString(buf, str)
+		return
+
+	case MYSQL_TYPE_TIME:
+		d := unpackTime(int64(DecodeU64(jsonSlice(data, 0, 8))))
+		jsonString(buf, mysql.DurationString(d))
+		return
+	}
+	// The same format as used by MySQL for other opaque values
+	jsonString(buf, fmt.Sprintf(
+		"base64:type%d:%s", typ, base64.StdEncoding.EncodeToString(data),
+	))
+}
@@ -0,0 +1,547 @@
+package native
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ziutek/mymysql/mysql"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Table definition received in TABLE_MAP_EVENT. It describes columns of rows
+// in following WRITE/UPDATE/DELETE_ROWS_EVENTs.
+type BinlogTable struct {
+	Id       uint64
+	Db       string
+	Table    string
+	Types    []byte   // Column types (MYSQL_TYPE_*)
+	Meta     []uint16 // Column metadata (length, precision, fsp...)
+	Nullable []bool
+
+	// Optional metadata (MySQL 8.0 with binlog_row_metadata=FULL), nil
+	// if not sent by the server.
+	Unsigned   []bool     // Signedness of columns (ignored if not numeric)
+	Names      []string   // Column names
+	EnumValues [][]string // Values of ENUM columns (nil for other columns)
+	SetValues  [][]string // Values of SET columns (nil for other columns)
+}
+
+// Decoded WRITE/UPDATE/DELETE_ROWS_EVENT. Values have the same types as in
+// rows returned by prepared statements. Columns not included in the row
+// image (binlog_row_image=MINIMAL) are nil.
+//
+// ENUM and SET values are returned as []byte (like in prepared statement
+// rows) only if the table definition contains their values (EnumValues,
+// SetValues). Otherwise binlog doesn't contain their names, so ENUM is
+// returned as index of its value (uint16, 0 for invalid value) and SET as
+// bitmap of its values (uint64).
+type BinlogRows struct {
+	Table  *BinlogTable
+	Flags  uint16
+	Before []mysql.Row // Row images before change (UPDATE, DELETE)
+	After  []mysql.Row // Row images after change (WRITE, UPDATE)
+}
+
+// Returns table definition for table id used in rows events or nil if
+// TABLE_MAP_EVENT for this id wasn't received.
+func (bs *BinlogStream) Table(id uint64) *BinlogTable {
+	return bs.tables[id]
+}
+
+func (bs *BinlogStream) readTableId(rd *bytes.Reader, typ byte) uint64 {
+	if int(typ) <= len(bs.post_hdr) && bs.post_hdr[typ-1] == 6 {
+		// Old format with 4 byte table id
+		return uint64(readU32(rd))
+	}
+	return DecodeU64(read(rd, 6))
+}
+
+func (bs *BinlogStream) parseTableMap(ev *BinlogEvent) *BinlogTable {
+	rd := bytes.NewReader(ev.Data)
+	tab := new(BinlogTable)
+	tab.Id = bs.readTableId(rd, ev.Type)
+	read(rd, 2) // Flags
+	tab.Db = string(read(rd, int(readByte(rd))))
+	read(rd, 1)
+	tab.Table = string(read(rd, int(readByte(rd))))
+	read(rd, 1)
+	n := int(readLCB(rd))
+	tab.Types = read(rd, n)
+	tab.Meta = make([]uint16, n)
+	meta := bytes.NewReader(readBin(rd))
+	for ii, typ := range tab.Types {
+		switch typ {
+		case MYSQL_TYPE_FLOAT, MYSQL_TYPE_DOUBLE, MYSQL_TYPE_BLOB,
+			MYSQL_TYPE_GEOMETRY, MYSQL_TYPE_JSON, MYSQL_TYPE_TIMESTAMP2,
+			MYSQL_TYPE_DATETIME2, MYSQL_TYPE_TIME2:
+			tab.Meta[ii] = uint16(readByte(meta))
+		case MYSQL_TYPE_VARCHAR, MYSQL_TYPE_VAR_STRING, MYSQL_TYPE_BIT:
+			tab.Meta[ii] = readU16(meta)
+		case MYSQL_TYPE_NEWDECIMAL, MYSQL_TYPE_STRING, MYSQL_TYPE_ENUM,
+			MYSQL_TYPE_SET:
+			// Big endian: precision/scale or real type/length
+			b := read(meta, 2)
+			tab.Meta[ii] = uint16(b[0])<<8 | uint16(b[1])
+		}
+	}
+	tab.Nullable = make([]bool, n)
+	nulls := read(rd, (n+7)>>3)
+	for ii := range tab.Nullable {
+		tab.Nullable[ii] = bitIsSet(nulls, ii)
+	}
+	for rd.Len() > 0 {
+		tab.optionalMeta(readByte(rd), bytes.NewReader(readBin(rd)))
+	}
+	return tab
+}
+
+func bitIsSet(bitmap []byte, n int) bool {
+	return bitmap[n>>3]&(1<<uint(n&7)) != 0
+}
+
+func (tab *BinlogTable) isNumeric(ii int) bool {
+	switch tab.Types[ii] {
+	case MYSQL_TYPE_TINY, MYSQL_TYPE_SHORT, MYSQL_TYPE_INT24,
+		MYSQL_TYPE_LONG, MYSQL_TYPE_LONGLONG, MYSQL_TYPE_FLOAT,
+		MYSQL_TYPE_DOUBLE, MYSQL_TYPE_NEWDECIMAL:
+		return true
+	}
+	return false
+}
+
+// Returns real column type and its length for string types
+func (tab *BinlogTable) stringType(ii int) (typ byte, length int) {
+	typ, meta := tab.Types[ii], tab.Meta[ii]
+	if meta < 256 {
+		return typ, int(meta)
+	}
+	b0, b1 := byte(meta>>8), byte(meta)
+	if b0&0x30 != 0x30 {
+		// Length longer than 255 (upper bits are in b0)
+		return b0 | 0x30, int(uint16(b1) | uint16((b0&0x30)^0x30)<<4)
+	}
+	return b0, int(b1)
+}
+
+func readStrValues(rd *bytes.Reader) [][]string {
+	var vals [][]string
+	for rd.Len() > 0 {
+		v := make([]string, readLCB(rd))
+		for ii := range v {
+			v[ii] = readStr(rd)
+		}
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+func (tab *BinlogTable) optionalMeta(typ byte, rd *bytes.Reader) {
+	switch typ {
+	case 1: // SIGNEDNESS - one bit per numeric column, MSB first
+		bits := read(rd, rd.Len())
+		tab.Unsigned = make([]bool, len(tab.Types))
+		nn := 0
+		for ii := range tab.Types {
+			if tab.isNumeric(ii) {
+				if nn>>3 >= len(bits) {
+					panic(mysql.ErrBinlogEvent)
+				}
+				tab.Unsigned[ii] = bits[nn>>3]&(0x80>>uint(nn&7)) != 0
+				nn++
+			}
+		}
+	case 4: // COLUMN_NAME
+		for rd.Len() > 0 {
+			tab.Names = append(tab.Names, readStr(rd))
+		}
+	case 5, 6: // SET_STR_VALUE, ENUM_STR_VALUE
+		vals := readStrValues(rd)
+		real := byte(MYSQL_TYPE_SET)
+		out := make([][]string, len(tab.Types))
+		if typ == 6 {
+			real = MYSQL_TYPE_ENUM
+			tab.EnumValues = out
+		} else {
+			tab.SetValues = out
+		}
+		for ii := range tab.Types {
+			if t, _ := tab.stringType(ii); t == real && len(vals) > 0 {
+				out[ii] = vals[0]
+				vals = vals[1:]
+			}
+		}
+	}
+}
+
+// Decodes WRITE/UPDATE/DELETE_ROWS_EVENT (version 1 or 2) using table
+// definition from the preceding TABLE_MAP_EVENT.
+func (bs *BinlogStream) Rows(ev *BinlogEvent) (rows *BinlogRows, err error) {
+	defer catchError(&err)
+
+	var write, update, v2 bool
+	switch ev.Type {
+	case WRITE_ROWS_EVENTv2:
+		v2 = true
+		fallthrough
+	case WRITE_ROWS_EVENTv1:
+		write = true
+	case UPDATE_ROWS_EVENTv2:
+		v2 = true
+		fallthrough
+	case UPDATE_ROWS_EVENTv1:
+		update = true
+	case DELETE_ROWS_EVENTv2:
+		v2 = true
+	case DELETE_ROWS_EVENTv1:
+	default:
+		return nil, mysql.ErrBinlogEvent
+	}
+	rd := bytes.NewReader(ev.Data)
+	rows = new(BinlogRows)
+	if rows.Table = bs.tables[bs.readTableId(rd, ev.Type)]; rows.Table == nil {
+		return nil, mysql.ErrBinlogNoTable
+	}
+	rows.Flags = readU16(rd)
+	if v2 {
+		// Extra data length includes its own two bytes
+		if n := int(readU16(rd)); n > 2 {
+			read(rd, n-2)
+		}
+	}
+	n := int(readLCB(rd))
+	if n != len(rows.Table.Types) {
+		panic(mysql.ErrBinlogEvent)
+	}
+	present := read(rd, (n+7)>>3)
+	present_after := present
+	if update {
+		present_after = read(rd, (n+7)>>3)
+	}
+	for rd.Len() > 0 {
+		if write {
+			rows.After = append(rows.After, rows.Table.readRow(rd, present))
+			continue
+		}
+		rows.Before = append(rows.Before, rows.Table.readRow(rd, present))
+		if update {
+			rows.After = append(
+				rows.After, rows.Table.readRow(rd, present_after),
+			)
+		}
+	}
+	return
+}
+
+func (tab *BinlogTable) readRow(rd *bytes.Reader, present []byte) mysql.Row {
+	row := make(mysql.Row, len(tab.Types))
+	cnt := 0
+	for ii := range row {
+		if bitIsSet(present, ii) {
+			cnt++
+		}
+	}
+	nulls := read(rd, (cnt+7)>>3)
+	nn := 0
+	for ii := range row {
+		if !bitIsSet(present, ii) {
+			continue
+		}
+		if !bitIsSet(nulls, nn) {
+			row[ii] = tab.readValue(rd, ii)
+		}
+		nn++
+	}
+	return row
+}
+
+// Reads big endian unsigned integer
+func readBE(rd *bytes.Reader, n int) (v uint64) {
+	for _, b := range read(rd, n) {
+		v = v<<8 | uint64(b)
+	}
+	return
+}
+
+// Reads fractional part of TIME2, DATETIME2, TIMESTAMP2 as microseconds
+func readFrac(rd *bytes.Reader, fsp uint16) int64 {
+	switch fsp {
+	case 1, 2:
+		return int64(readBE(rd, 1)) * 10000
+	case 3, 4:
+		return int64(readBE(rd, 2)) * 100
+	case 5, 6:
+		return int64(readBE(rd, 3))
+	}
+	return 0
+}
+
+// Unpacks packed datetime (see MySQL my_time.h).
+func unpackDatetime(packed int64) (y, mon, d, h, m, s, us int) {
+	if packed < 0 {
+		packed = -packed
+	}
+	ymdhms := packed >> 24
+	ymd := ymdhms >> 17
+	ym := ymd >> 5
+	hms := ymdhms % (1 << 17)
+	return int(ym / 13), int(ym % 13), int(ymd % (1 << 5)),
+		int(hms >> 12), int((hms >> 6) % (1 << 6)), int(hms % (1 << 6)),
+		int(packed % (1 << 24))
+}
+
+// Converts packed time (see MySQL my_time.h) to time.Duration.
+func unpackTime(packed int64) time.Duration {
+	sign := time.Duration(1)
+	if packed < 0 {
+		sign = -1
+		packed = -packed
+	}
+	hms := packed >> 24
+	d := time.Duration((hms>>12)%(1<<10))*time.Hour +
+		time.Duration((hms>>6)%(1<<6))*time.Minute +
+		time.Duration(hms%(1<<6))*time.Second +
+		time.Duration(packed%(1<<24))*time.Microsecond
+	return sign * d
+}
+
+var dig2bytes = [10]int{0, 1, 1, 2, 2, 3, 3, 4, 4, 4}
+
+func decimalSize(prec, scale int) int {
+	intg := prec - scale
+	return intg/9*4 + dig2bytes[intg%9] + scale/9*4 + dig2bytes[scale%9]
+}
+
+// Decodes DECIMAL in MySQL binary format (see decimal2bin in MySQL
+// strings/decimal.c) to its text representation.
+func decodeDecimal(bin []byte, prec, scale int) string {
+	if len(bin) != decimalSize(prec, scale) {
+		panic(mysql.ErrBinlogEvent)
+	}
+	buf := make([]byte, len(bin))
+	copy(buf, bin)
+	negative := buf[0]&0x80 == 0
+	buf[0] ^= 0x80
+	if negative {
+		for ii := range buf {
+			buf[ii] ^= 0xff
+		}
+	}
+	rd := bytes.NewReader(buf)
+	var out bytes.Buffer
+	if negative {
+		out.WriteByte('-')
+	}
+	intg := prec - scale
+	// Leading digits - don't write leading zeros
+	started := false
+	put := func(v uint64, digits int) {
+		if started {
+			fmt.Fprintf(&out, "%0*d", digits, v)
+		} else if v != 0 {
+			fmt.Fprint(&out, v)
+			started = true
+		}
+	}
+	if n := intg % 9; n != 0 {
+		put(readBE(rd, dig2bytes[n]), n)
+	}
+	for ii := 0; ii < intg/9; ii++ {
+		put(readBE(rd, 4), 9)
+	}
+	if !started {
+		out.WriteByte('0')
+	}
+	if scale > 0 {
+		out.WriteByte('.')
+		for ii := 0; ii < scale/9; ii++ {
+			fmt.Fprintf(&out, "%09d", readBE(rd, 4))
+		}
+		if n := scale % 9; n != 0 {
+			fmt.Fprintf(&out, "%0*d", n, readBE(rd, dig2bytes[n]))
+		}
+	}
+	return out.String()
+}
+
+// Reads a value of ii-th column. Types of returned values match types
+// returned by getBinRowPacket (except ENUM and SET without their values in
+// table definition, see BinlogRows).
+func (tab *BinlogTable) readValue(rd *bytes.Reader, ii int) interface{} {
+	typ, meta := tab.Types[ii], tab.Meta[ii]
+	unsigned := tab.Unsigned != nil && tab.Unsigned[ii]
+	switch typ {
+	case MYSQL_TYPE_TINY:
+		if unsigned {
+			return readByte(rd)
+		}
+		return int8(readByte(rd))
+
+	case MYSQL_TYPE_SHORT:
+		if unsigned {
+			return readU16(rd)
+		}
+		return int16(readU16(rd))
+
+	case MYSQL_TYPE_INT24:
+		if unsigned {
+			return readU24(rd)
+		}
+		return int32(readU24(rd)<<8) >> 8
+
+	case MYSQL_TYPE_LONG:
+		if unsigned {
+			return readU32(rd)
+		}
+		return int32(readU32(rd))
+
+	case MYSQL_TYPE_LONGLONG:
+		if unsigned {
+			return readU64(rd)
+		}
+		return int64(readU64(rd))
+
+	case MYSQL_TYPE_FLOAT:
+		return math.Float32frombits(readU32(rd))
+
+	case MYSQL_TYPE_DOUBLE:
+		return math.Float64frombits(readU64(rd))
+
+	case MYSQL_TYPE_NEWDECIMAL:
+		prec, scale := int(meta>>8), int(meta&0xff)
+		dec := decodeDecimal(read(rd, decimalSize(prec, scale)), prec, scale)
+		f, err := strconv.ParseFloat(dec, 64)
+		if err != nil {
+			panic(mysql.ErrBinlogEvent)
+		}
+		return f
+
+	case MYSQL_TYPE_YEAR:
+		if y := readByte(rd); y != 0 {
+			return uint16(y) + 1900
+		}
+		return uint16(0)
+
+	case MYSQL_TYPE_DATE, MYSQL_TYPE_NEWDATE:
+		v := readU24(rd)
+		return mysql.Date{
+			Year: int16(v >> 9), Month: byte(v >> 5 & 15), Day: byte(v & 31),
+		}
+
+	case MYSQL_TYPE_TIMESTAMP:
+		if s := readU32(rd); s != 0 {
+			return time.Unix(int64(s), 0)
+		}
+		return mysql.ZeroTime
+
+	case MYSQL_TYPE_TIMESTAMP2:
+		s := int64(readBE(rd, 4))
+		us := readFrac(rd, meta)
+		if s == 0 && us == 0 {
+			return mysql.ZeroTime
+		}
+		return time.Unix(s, us*1000)
+
+	case MYSQL_TYPE_DATETIME:
+		v := readU64(rd) // YYYYMMDDhhmmss
+		d, t := int(v/1000000), int(v%1000000)
+		return datetimeValue(
+			d/10000, d/100%100, d%100, t/10000, t/100%100, t%100, 0,
+		)
+
+	case MYSQL_TYPE_DATETIME2:
+		intpart := int64(readBE(rd, 5)) - 0x8000000000
+		return datetimeValue(unpackDatetime(intpart<<24 + readFrac(rd, meta)))
+
+	case MYSQL_TYPE_TIME:
+		v := int32(readU24(rd)<<8) >> 8 // [-]HHMMSS
+		sign := time.Duration(1)
+		if v < 0 {
+			sign = -1
+			v = -v
+		}
+		return sign * (time.Duration(v/10000)*time.Hour +
+			time.Duration(v/100%100)*time.Minute +
+			time.Duration(v%100)*time.Second)
+
+	case MYSQL_TYPE_TIME2:
+		var packed int64
+		switch meta {
+		case 5, 6:
+			packed = int64(readBE(rd, 6)) - 0x800000000000
+		default:
+			intpart := int64(readBE(rd, 3)) - 0x800000
+			frac := readFrac(rd, meta)
+			if intpart < 0 && frac != 0 {
+				// Negative value with fractional part is stored as
+				// (intpart+1) + (frac-1s)
+				intpart++
+				switch meta {
+				case 1, 2:
+					frac -= 0x100 * 10000
+				case 3, 4:
+					frac -= 0x10000 * 100
+				}
+			}
+			packed = intpart<<24 + frac
+		}
+		return unpackTime(packed)
+
+	case MYSQL_TYPE_VARCHAR, MYSQL_TYPE_VAR_STRING:
+		if meta < 256 {
+			return read(rd, int(readByte(rd)))
+		}
+		return read(rd, int(readU16(rd)))
+
+	case MYSQL_TYPE_STRING, MYSQL_TYPE_ENUM, MYSQL_TYPE_SET:
+		real, length := tab.stringType(ii)
+		switch real {
+		case MYSQL_TYPE_ENUM:
+			var idx uint16
+			if length == 2 {
+				// ENUM with more than 255 values
+				idx = readU16(rd)
+			} else {
+				idx = uint16(readByte(rd))
+			}
+			if tab.EnumValues == nil || tab.EnumValues[ii] == nil {
+				return idx
+			}
+			if idx == 0 || int(idx) > len(tab.EnumValues[ii]) {
+				return []byte{}
+			}
+			return []byte(tab.EnumValues[ii][idx-1])
+
+		case MYSQL_TYPE_SET:
+			bits := DecodeU64(read(rd, length))
+			if tab.SetValues == nil || tab.SetValues[ii] == nil {
+				return bits
+			}
+			var vals []string
+			for jj, v := range tab.SetValues[ii] {
+				if bits&(1<<uint(jj)) != 0 {
+					vals = append(vals, v)
+				}
+			}
+			return []byte(strings.Join(vals, ","))
+		}
+		if length < 256 {
+			return read(rd, int(readByte(rd)))
+		}
+		return read(rd, int(readU16(rd)))
+
+	case MYSQL_TYPE_BIT:
+		nbits := int(meta>>8)*8 + int(meta&0xff)
+		return read(rd, (nbits+7)/8)
+
+	case MYSQL_TYPE_BLOB, MYSQL_TYPE_GEOMETRY, MYSQL_TYPE_JSON:
+		// Metadata contains number of bytes of length prefix
+		data := read(rd, int(DecodeU64(read(rd, int(meta)))))
+		if typ == MYSQL_TYPE_JSON {
+			return decodeJsonBin(data)
+		}
+		return data
+	}
+	panic(mysql.ErrUnkMySQLType)
+}
@@ -0,0 +1,240 @@
+package native
+
+import (
+	"bytes"
+	"github.com/ziutek/mymysql/mysql"
+	"hash/crc32"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func binlogEvent(typ byte, log_pos uint32, body []byte, checksum bool) []byte {
+	size := _BINLOG_EVENT_HEADER_LEN + len(body)
+	if checksum {
+		size += 4
+	}
+	var buf bytes.Buffer
+	buf.Write(EncodeU32(1400000000)) // Timestamp
+	buf.WriteByte(typ)
+	buf.Write(EncodeU32(1)) // Server id
+	buf.Write(EncodeU32(uint32(size)))
+	buf.Write(EncodeU32(log_pos))
+	buf.Write(EncodeU16(0)) // Flags
+	buf.Write(body)
+	if checksum {
+		buf.Write(EncodeU32(crc32.ChecksumIEEE(buf.Bytes())))
+	}
+	return buf.Bytes()
+}
+
+func queryEventBody(sql string) []byte {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 8)) // thread_id, exec_time
+	buf.WriteByte(4)           // db_len
+	buf.Write(EncodeU16(0))    // error_code
+	buf.Write(EncodeU16(0))    // status_vars_len
+	buf.WriteString("test\x00")
+	buf.WriteString(sql)
+	return buf.Bytes()
+}
+
+func TestBinlogCheckpoint(t *testing.T) {
+	sid, _ := mysql.ParseUuid("3e11fa47-71ca-11e1-9e33-c80aa9429562")
+	gtids, _ := mysql.ParseGtidSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5")
+	bs := &BinlogStream{pos: BinlogPos{Gtids: gtids.Clone()}, checksum: true}
+
+	gtid := func(gno int64) []byte {
+		return append(append([]byte{1}, sid[:]...), EncodeU64(uint64(gno))...)
+	}
+	rotate := append(EncodeU64(4), "bin.000002"...)
+	events := []struct {
+		buf  []byte
+		pos  uint32
+		exp  string
+		file string
+	}{
+		{binlogEvent(ROTATE_EVENT, 0, rotate, true), 4, "1-5", "bin.000002"},
+		{binlogEvent(GTID_EVENT, 100, gtid(6), true), 4, "1-5", "bin.000002"},
+		{binlogEvent(QUERY_EVENT, 200, queryEventBody("BEGIN"), true),
+			4, "1-5", "bin.000002"},
+		{binlogEvent(WRITE_ROWS_EVENTv2, 300, []byte{1, 2, 3}, true),
+			4, "1-5", "bin.000002"},
+		{binlogEvent(XID_EVENT, 400, EncodeU64(77), true),
+			400, "1-6", "bin.000002"},
+		{binlogEvent(GTID_EVENT, 500, gtid(7), true), 400, "1-6", "bin.000002"},
+		{binlogEvent(QUERY_EVENT, 600, queryEventBody("CREATE TABLE t (i int)"),
+			true), 600, "1-7", "bin.000002"},
+	}
+	for ii, e := range events {
+		ev := bs.parseEvent(e.buf)
+		bs.track(ev)
+		cp := bs.Checkpoint()
+		exp := "3e11fa47-71ca-11e1-9e33-c80aa9429562:" + e.exp
+		if cp.Pos != e.pos || cp.File != e.file || cp.Gtids.String() != exp {
+			t.Fatalf("%d: bad checkpoint: %s:%d %s", ii, cp.File, cp.Pos,
+				cp.Gtids)
+		}
+	}
+	if gtids.ContainsGtid(sid, 6) {
+		t.Fatal("Checkpoint modified initial GTID set")
+	}
+}
+
+func TestBinlogChecksum(t *testing.T) {
+	bs := &BinlogStream{checksum: true}
+	buf := binlogEvent(XID_EVENT, 400, EncodeU64(77), true)
+	ev := bs.parseEvent(buf)
+	if !bytes.Equal(ev.Data, EncodeU64(77)) || ev.LogPos != 400 {
+		t.Fatalf("Bad event: %+v", ev)
+	}
+	buf[len(buf)-5]++
+	func() {
+		defer func() {
+			if e := recover(); e != mysql.ErrBinlogChecksum {
+				t.Fatalf("Bad checksum not detected: %v", e)
+			}
+		}()
+		bs.parseEvent(buf)
+	}()
+
+	// FDE of MySQL 5.5 disables checksums
+	fde := make([]byte, 2+50+4+1+30)
+	copy(fde[2:], "5.5.30-log")
+	bs.parseEvent(binlogEvent(FORMAT_DESCRIPTION_EVENT, 120, fde, false))
+	if bs.checksum {
+		t.Fatal("Checksum enabled by MySQL 5.5 FDE")
+	}
+}
+
+func TestBinlogDecimal(t *testing.T) {
+	bin := []byte{0x81, 0x0d, 0xfb, 0x38, 0xd2, 0x04, 0xd2}
+	if d := decodeDecimal(bin, 14, 4); d != "1234567890.1234" {
+		t.Fatalf("Bad decimal: %s", d)
+	}
+	bin = []byte{0x7e, 0xf2, 0x04, 0xc7, 0x2d, 0xfb, 0x2d}
+	if d := decodeDecimal(bin, 14, 4); d != "-1234567890.1234" {
+		t.Fatalf("Bad decimal: %s", d)
+	}
+	bin = []byte{0x80, 0x00, 0x05}
+	if d := decodeDecimal(bin, 5, 3); d != "0.005" {
+		t.Fatalf("Bad decimal: %s", d)
+	}
+}
+
+func TestBinlogJson(t *testing.T) {
+	docs := []struct {
+		bin []byte
+		exp string
+	}{
+		{[]byte{}, "null"},
+		{[]byte{_JSONB_SMALL_OBJECT, 1, 0, 12, 0, 11, 0, 1, 0,
+			_JSONB_INT16, 1, 0, 'a'}, `{"a":1}`},
+		{[]byte{_JSONB_SMALL_ARRAY, 2, 0, 12, 0, _JSONB_STRING, 10, 0,
+			_JSONB_LITERAL, 1, 0, 1, '"'}, `["\"",true]`},
+		{[]byte{_JSONB_DOUBLE, 0, 0, 0, 0, 0, 0, 0xf8, 0x3f}, "1.5"},
+	}
+	for _, d := range docs {
+		if js := string(decodeJsonBin(d.bin)); js != d.exp {
+			t.Fatalf("Bad JSON: %s != %s", js, d.exp)
+		}
+	}
+}
+
+func TestBinlogRows(t *testing.T) {
+	var tm bytes.Buffer
+	tm.Write([]byte{7, 0, 0, 0, 0, 0}) // Table id
+	tm.Write([]byte{0, 0})             // Flags
+	tm.WriteString("\x04test\x00\x01t\x00")
+	types := []byte{
+		MYSQL_TYPE_LONG, MYSQL_TYPE_VARCHAR, MYSQL_TYPE_NEWDECIMAL,
+		MYSQL_TYPE_DATETIME2, MYSQL_TYPE_TIME2, MYSQL_TYPE_JSON,
+		MYSQL_TYPE_STRING, MYSQL_TYPE_INT24,
+	}
+	writeBin(&tm, types)
+	writeBin(&tm, []byte{
+		255, 0, // VARCHAR(255)
+		14, 4, // DECIMAL(14,4)
+		0,                  // DATETIME2(0)
+		0,                  // TIME2(0)
+		4,                  // JSON
+		MYSQL_TYPE_ENUM, 1, // ENUM
+	})
+	tm.WriteByte(0xff) // Nullable
+	tm.WriteByte(1)    // SIGNEDNESS
+	writeBin(&tm, []byte{0x80})
+	tm.WriteByte(6) // ENUM_STR_VALUE
+	writeBin(&tm, []byte{2, 1, 'x', 1, 'y'})
+
+	bs := new(BinlogStream)
+	bs.track(&BinlogEvent{Type: TABLE_MAP_EVENT, Data: tm.Bytes()})
+	tab := bs.Table(7)
+	if tab == nil || tab.Db != "test" || tab.Table != "t" ||
+		!tab.Unsigned[0] || tab.Unsigned[2] ||
+		tab.EnumValues[6][1] != "y" {
+		t.Fatalf("Bad table: %+v", tab)
+	}
+
+	var rs bytes.Buffer
+	rs.Write([]byte{7, 0, 0, 0, 0, 0}) // Table id
+	rs.Write([]byte{0, 0})             // Flags
+	rs.Write([]byte{2, 0})             // Extra data length
+	rs.WriteByte(byte(len(types)))
+	rs.WriteByte(0xff)                                         // Columns present
+	rs.WriteByte(0x80)                                         // NULL bitmap
+	rs.Write(EncodeU32(0xfffffffe))                            // LONG UNSIGNED
+	rs.WriteString("\x03abc")                                  // VARCHAR
+	rs.Write([]byte{0x7e, 0xf2, 0x04, 0xc7, 0x2d, 0xfb, 0x2d}) // DECIMAL
+	rs.Write([]byte{0x99, 0x95, 0x88, 0x51, 0x87})             // DATETIME2
+	rs.Write([]byte{0x80, 0x10, 0x83})                         // TIME2
+	js := []byte{_JSONB_LITERAL, 2}
+	rs.Write(append(EncodeU32(uint32(len(js))), js...)) // JSON
+	rs.WriteByte(2)                                     // ENUM
+
+	rows, err := bs.Rows(&BinlogEvent{Type: WRITE_ROWS_EVENTv2, Data: rs.Bytes()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := mysql.Row{
+		uint32(0xfffffffe), []byte("abc"), -1234567890.1234,
+		time.Date(2015, 3, 4, 5, 6, 7, 0, time.Local),
+		time.Duration(3723e9), []byte("false"), []byte("y"), nil,
+	}
+	if len(rows.After) != 1 || rows.Before != nil ||
+		!reflect.DeepEqual(rows.After[0], exp) {
+		t.Fatalf("Bad rows: %#v", rows.After)
+	}
+}
+
+func TestBinlogEnum2(t *testing.T) {
+	var tm bytes.Buffer
+	tm.Write([]byte{8, 0, 0, 0, 0, 0}) // Table id
+	tm.Write([]byte{0, 0})             // Flags
+	tm.WriteString("\x04test\x00\x01e\x00")
+	types := []byte{MYSQL_TYPE_STRING, MYSQL_TYPE_LONG}
+	writeBin(&tm, types)
+	writeBin(&tm, []byte{MYSQL_TYPE_ENUM, 2}) // ENUM with 2 byte index
+	tm.WriteByte(0)                           // Nullable
+
+	bs := new(BinlogStream)
+	bs.track(&BinlogEvent{Type: TABLE_MAP_EVENT, Data: tm.Bytes()})
+
+	var rs bytes.Buffer
+	rs.Write([]byte{8, 0, 0, 0, 0, 0}) // Table id
+	rs.Write([]byte{0, 0})             // Flags
+	rs.Write([]byte{2, 0})             // Extra data length
+	rs.WriteByte(byte(len(types)))
+	rs.WriteByte(0x03)         // Columns present
+	rs.WriteByte(0)            // NULL bitmap
+	rs.Write([]byte{0x2c, 1})  // ENUM index 300
+	rs.Write(EncodeU32(12345)) // LONG
+
+	rows, err := bs.Rows(&BinlogEvent{Type: WRITE_ROWS_EVENTv2, Data: rs.Bytes()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := mysql.Row{uint16(300), int32(12345)}
+	if len(rows.After) != 1 || !reflect.DeepEqual(rows.After[0], exp) {
+		t.Fatalf("Bad rows: %#v", rows.After)
+	}
+}
@@ -0,0 +1,263 @@
+package native
+
+import (
+	"fmt"
+	"github.com/ziutek/mymysql/mysql"
+	"reflect"
+	"regexp"
+	"unicode/utf8"
+)
+
+func inRange(b, min, max byte) bool {
+	return b >= min && b <= max
+}
+
+// Describes multibyte character set: mbCharset function returns n > 1 if s
+// starts with valid n-byte character, -1 if s starts with lead byte of
+// invalid or incomplete multibyte character or 1 otherwise.
+type mbCharset func(s string) int
+
+// Returns mbCharset for double byte character set
+func dbcs(lead, trail func(b byte) bool) mbCharset {
+	return func(s string) int {
+		if !lead(s[0]) {
+			return 1
+		}
+		if len(s) > 1 && trail(s[1]) {
+			return 2
+		}
+		return -1
+	}
+}
+
+var (
+	gbkChar = dbcs(
+		func(b byte) bool { return inRange(b, 0x81, 0xfe) },
+		func(b byte) bool {
+			return inRange(b, 0x40, 0x7e) || inRange(b, 0x80, 0xfe)
+		},
+	)
+	big5Char = dbcs(
+		func(b byte) bool { return inRange(b, 0xa1, 0xf9) },
+		func(b byte) bool {
+			return inRange(b, 0x40, 0x7e) || inRange(b, 0xa1, 0xfe)
+		},
+	)
+	sjisChar = dbcs(
+		func(b byte) bool {
+			return inRange(b, 0x81, 0x9f) || inRange(b, 0xe0, 0xfc)
+		},
+		func(b byte) bool {
+			return inRange(b, 0x40, 0x7e) || inRange(b, 0x80, 0xfc)
+		},
+	)
+	euckrChar = dbcs(
+		func(b byte) bool { return inRange(b, 0x81, 0xfe) },
+		func(b byte) bool {
+			return inRange(b, 0x41, 0x5a) || inRange(b, 0x61, 0x7a) ||
+				inRange(b, 0x81, 0xfe)
+		},
+	)
+	gb2312Char = dbcs(
+		func(b byte) bool { return inRange(b, 0xa1, 0xf7) },
+		func(b byte) bool { return inRange(b, 0xa1, 0xfe) },
+	)
+)
+
+// GB18030: GBK double byte characters and four byte characters:
+// [81-fe][30-39][81-fe][30-39]
+func gb18030Char(s string) int {
+	if !inRange(s[0], 0x81, 0xfe) {
+		return 1
+	}
+	if len(s) > 1 && inRange(s[1], 0x30, 0x39) {
+		if len(s) > 3 && inRange(s[2], 0x81, 0xfe) &&
+			inRange(s[3], 0x30, 0x39) {
+			return 4
+		}
+		return -1
+	}
+	return gbkChar(s)
+}
+
+// EUC-JP: [a1-fe][a1-fe], 8e[a1-df] (half width katakana) and
+// 8f[a1-fe][a1-fe] (JIS X 0212)
+func ujisChar(s string) int {
+	c := s[0]
+	switch {
+	case c == 0x8e:
+		if len(s) > 1 && inRange(s[1], 0xa1, 0xdf) {
+			return 2
+		}
+	case c == 0x8f:
+		if len(s) > 2 && inRange(s[1], 0xa1, 0xfe) &&
+			inRange(s[2], 0xa1, 0xfe) {
+			return 3
+		}
+	case inRange(c, 0xa1, 0xfe):
+		if len(s) > 1 && inRange(s[1], 0xa1, 0xfe) {
+			return 2
+		}
+	default:
+		return 1
+	}
+	return -1
+}
+
+// Multibyte character sets that aren't ASCII transparent. For other
+// supported character sets (eg. utf8, latin1) byte by byte escaping is safe.
+var mbCharsets = map[string]mbCharset{
+	"big5":    big5Char,
+	"cp932":   sjisChar,
+	"eucjpms": ujisChar,
+	"euckr":   euckrChar,
+	"gb18030": gb18030Char,
+	"gb2312":  gb2312Char,
+	"gbk":     gbkChar,
+	"sjis":    sjisChar,
+	"ujis":    ujisChar,
+}
+
+// Returns description of connection character set or nil if it doesn't need
+// special escaping.
+func (my *Conn) mbCharset() mbCharset {
+	return mbCharsets[mysql.CharsetName(my.collation)]
+}
+
+// Matches statements that change client character set
+var setCharsetRegexp = regexp.MustCompile(
+	"(?i)(?:^|;)\\s*SET\\s+(?:NAMES|CHARACTER\\s+SET|CHARSET)\\s+" +
+		"['\"`]?(\\w+)['\"`]?(?:\\s+COLLATE\\s+['\"`]?(\\w+))?",
+)
+
+// Updates connection collation if sql (successfully executed) changes it.
+func (my *Conn) trackCharset(sql string) {
+	m := setCharsetRegexp.FindAllStringSubmatch(sql, -1)
+	if m == nil {
+		return
+	}
+	last := m[len(m)-1]
+	name := last[1]
+	if last[2] != "" {
+		name = last[2]
+	}
+	if id, ok := mysql.CollationId(name); ok {
+		my.collation = id
+	}
+}
+
+// Returns collation id that should be sent in handshake response and SET
+// NAMES query if collation can't be sent in handshake (its id > 255).
+func (my *Conn) handshakeCollation() (id uint16, set_names string) {
+	id, ok := mysql.CollationId(my.charset)
+	if !ok {
+		// Server default collation
+		return uint16(my.info.lang), ""
+	}
+	if id > 255 {
+		set_names = setNamesQuery(id)
+		id, _ = mysql.CollationId(mysql.CharsetName(id))
+	}
+	return
+}
+
+func setNamesQuery(id uint16) string {
+	return fmt.Sprintf(
+		"SET NAMES %s COLLATE %s",
+		mysql.CharsetName(id), mysql.CollationName(id),
+	)
+}
+
+// Sets character set (eg. "utf8mb4") or collation (eg. "utf8mb4_unicode_ci")
+// that will be used by connection. It is sent to the server during connect
+// (without additional SET NAMES round trip), so it should be called before
+// Connect. Empty name means server default collation.
+func (my *Conn) SetCharset(name string) error {
+	if name != "" {
+		if _, ok := mysql.CollationId(name); !ok {
+			return mysql.ErrUnkCharset
+		}
+	}
+	my.charset = name
+	return nil
+}
+
+// Changes character set or collation of established connection (using
+// SET NAMES). New charset is used after reconnect too.
+func (my *Conn) ChangeCharset(name string) (err error) {
+	defer catchError(&err)
+
+	if my.net_conn == nil {
+		return mysql.ErrNotConn
+	}
+	if my.unreaded_reply {
+		return mysql.ErrUnreadedReply
+	}
+	id, ok := mysql.CollationId(name)
+	if !ok {
+		return mysql.ErrUnkCharset
+	}
+	my.sendCmd(_COM_QUERY, setNamesQuery(id))
+	my.getResponse()
+	my.charset = name
+	my.collation = id
+	return
+}
+
+// Returns name of the collation used by connection.
+func (my *Conn) Collation() string {
+	return mysql.CollationName(my.collation)
+}
+
+// Enables/disables conversion of strings between UTF-8 and single byte
+// character sets (see mysql.CanTranscode). If enabled, text values in rows
+// are converted to UTF-8 according to their field character set, and
+// queries and string parameters of prepared statements bound after the call
+// are converted to the connection character set. Returns old value.
+//
+// Rows carry no character set information so the conversion is done when rows
+// are read: Row.Str and other Row methods return UTF-8 strings. Use
+// mysql.Field.DecodeString to convert values read without transcoding.
+func (my *Conn) SetTranscode(on bool) bool {
+	old := my.transcode
+	my.transcode = on
+	return old
+}
+
+// Converts text value of field to UTF-8 if my transcodes strings.
+func (my *Conn) decodeValue(field *mysql.Field, bin []byte) []byte {
+	if !my.transcode || field.IsBinary() || !mysql.CanTranscode(field.Charset) {
+		return bin
+	}
+	return []byte(mysql.DecodeString(field.Charset, bin))
+}
+
+// Converts UTF-8 string s to connection character set. String that isn't
+// valid UTF-8 (eg. contains escaped binary data) is assumed to be in
+// connection character set already and is returned unchanged.
+func (my *Conn) encodeString(s string) []byte {
+	if !utf8.ValidString(s) {
+		return []byte(s)
+	}
+	return mysql.EncodeString(my.collation, s)
+}
+
+// Converts sql to connection character set (see encodeString) if my
+// transcodes strings.
+func (my *Conn) encodeQuery(sql string) interface{} {
+	if !my.transcode || !mysql.CanTranscode(my.collation) {
+		return sql
+	}
+	return my.encodeString(sql)
+}
+
+// Like bindValue but if my transcodes strings, string values are converted
+// to connection character set during execution.
+func (my *Conn) bindValue(val reflect.Value) *paramValue {
+	pv := bindValue(val)
+	if my.transcode && mysql.CanTranscode(my.collation) &&
+		pv.typ == MYSQL_TYPE_STRING && pv.marshal == nil {
+		pv.marshal = my.stringEncoder(pv)
+	}
+	return pv
+}
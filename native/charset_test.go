@@ -0,0 +1,142 @@
+package native
+
+import (
+	"github.com/ziutek/mymysql/mysql"
+	"reflect"
+	"testing"
+)
+
+type escapeTest struct {
+	txt, str, quotes string
+}
+
+var escapeMbTests = map[string][]escapeTest{
+	// 0xbf5c is valid GBK character with backslash as trailing byte, 0xbf27
+	// isn't valid so 0xbf must be escaped.
+	"gbk": {
+		{"\xbf\x5c' \xbf'", "\xbf\x5c\\' \\\xbf\\'", "\xbf\x5c'' \xbf''"},
+		{"\xd5\xe2\\", "\xd5\xe2\\\\", "\xd5\xe2\\"},
+		{"a\xbf", "a\\\xbf", "a\xbf"},
+	},
+	"big5": {
+		{"\xa5\x5c'", "\xa5\x5c\\'", "\xa5\x5c''"},
+		{"\xa5'", "\\\xa5\\'", "\xa5''"},
+		{"\x80\\", "\x80\\\\", "\x80\\"},
+	},
+	"sjis": {
+		{"\x95\x5c'", "\x95\x5c\\'", "\x95\x5c''"},
+		{"\xe0\x5c\x0a", "\xe0\x5c\\n", "\xe0\x5c\x0a"},
+		{"\x95'", "\\\x95\\'", "\x95''"},
+		{"\xb1\\", "\xb1\\\\", "\xb1\\"}, // half width katakana
+	},
+	"cp932": {
+		{"\x95\x5c'", "\x95\x5c\\'", "\x95\x5c''"},
+	},
+	"gb18030": {
+		{"\x81\x30\x81\x30'", "\x81\x30\x81\x30\\'", "\x81\x30\x81\x30''"},
+		{"\xbf\x5c\"", "\xbf\x5c\\\"", "\xbf\x5c\""},
+		{"\x81\x30'", "\\\x81\x30\\'", "\x81\x30''"},
+		{"\x81'", "\\\x81\\'", "\x81''"},
+	},
+	"euckr": {
+		{"\xb0\xa1'", "\xb0\xa1\\'", "\xb0\xa1''"},
+		{"\xb0\\", "\\\xb0\\\\", "\xb0\\"},
+	},
+	"gb2312": {
+		{"\xb0\xa1'", "\xb0\xa1\\'", "\xb0\xa1''"},
+		{"\xb0'", "\\\xb0\\'", "\xb0''"},
+	},
+	"ujis": {
+		{"\xa4\xa2'", "\xa4\xa2\\'", "\xa4\xa2''"},
+		{"\x8e\xb1\\", "\x8e\xb1\\\\", "\x8e\xb1\\"},
+		{"\x8f\xb0\xa1'", "\x8f\xb0\xa1\\'", "\x8f\xb0\xa1''"},
+		{"\x8f\xb0'", "\\\x8f\\\xb0\\'", "\x8f\xb0''"},
+	},
+	"eucjpms": {
+		{"\xa4\xa2'", "\xa4\xa2\\'", "\xa4\xa2''"},
+	},
+	// ASCII transparent character sets are escaped byte by byte
+	"utf8": {
+		{"\xc5\x82'\\", "\xc5\x82\\'\\\\", "\xc5\x82''\\"},
+	},
+	"latin1": {
+		{"\xbf'", "\xbf\\'", "\xbf''"},
+	},
+}
+
+func TestEscapeMultibyte(t *testing.T) {
+	for cs, tests := range escapeMbTests {
+		id, ok := mysql.CollationId(cs)
+		if !ok {
+			t.Fatalf("unknown charset %s", cs)
+		}
+		my := &Conn{collation: id}
+		for _, et := range tests {
+			if out := escapeString(et.txt, my.mbCharset()); out != et.str {
+				t.Errorf("%s escapeString(%q)=%q exp=%q", cs, et.txt, out, et.str)
+			}
+			if out := escapeQuotes(et.txt, my.mbCharset()); out != et.quotes {
+				t.Errorf("%s escapeQuotes(%q)=%q exp=%q", cs, et.txt, out, et.quotes)
+			}
+		}
+	}
+}
+
+func TestTrackCharset(t *testing.T) {
+	tests := []struct {
+		sql string
+		exp string
+	}{
+		{"SELECT 1", "utf8_general_ci"},
+		{"set names gbk", "gbk_chinese_ci"},
+		{"SET NAMES 'sjis' COLLATE 'sjis_bin'", "sjis_bin"},
+		{"SET CHARACTER SET big5", "big5_chinese_ci"},
+		{"SET NAMES latin1; SET NAMES `euckr`", "euckr_korean_ci"},
+		{"SET NAMES unknown", "utf8_general_ci"},
+	}
+	for _, tt := range tests {
+		my := &Conn{collation: 33}
+		my.trackCharset(tt.sql)
+		if c := my.Collation(); c != tt.exp {
+			t.Errorf("%q: collation=%s exp=%s", tt.sql, c, tt.exp)
+		}
+	}
+}
+
+func TestTranscode(t *testing.T) {
+	latin2, _ := mysql.CollationId("latin2")
+	my := &Conn{collation: latin2}
+	field := &mysql.Field{Type: MYSQL_TYPE_VAR_STRING, Charset: latin2}
+	blob := &mysql.Field{Type: MYSQL_TYPE_BLOB, Charset: mysql.BINARY_COLLATION}
+	enc := []byte("\xbf\xf3\xb3w")
+	str := "żółw"
+
+	if v := my.decodeValue(field, enc); string(v) != string(enc) {
+		t.Errorf("decodeValue without transcoding: %q", v)
+	}
+	pv := my.bindValue(makeAddressable(reflect.ValueOf(&str)))
+	if pv.marshal != nil {
+		t.Error("string parameter encoder set without transcoding")
+	}
+
+	my.SetTranscode(true)
+	pv = my.bindValue(makeAddressable(reflect.ValueOf(&str)))
+	if v := my.decodeValue(field, enc); string(v) != str {
+		t.Errorf("decodeValue: %q exp: %q", v, str)
+	}
+	if v := my.decodeValue(blob, enc); string(v) != string(enc) {
+		t.Errorf("decodeValue of binary field: %q", v)
+	}
+	if v := pv.marshal(); string(v) != string(enc) {
+		t.Errorf("string parameter: %q exp: %q", v, enc)
+	}
+	if q := my.encodeQuery("SELECT '" + str + "'"); string(q.([]byte)) !=
+		"SELECT '"+string(enc)+"'" {
+		t.Errorf("encodeQuery: %q", q)
+	}
+	// Query with escaped binary data isn't valid UTF-8
+	bin := "INSERT t VALUES ('\xff\x00\xe9')"
+	if q := my.encodeQuery(bin); string(q.([]byte)) != bin {
+		t.Errorf("encodeQuery of binary data: %q", q)
+	}
+}
@@ -2,6 +2,7 @@ package native
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/ziutek/mymysql/mysql"
 	"io"
 	"time"
@@ -262,27 +263,20 @@ func readDuration(rd io.Reader) time.Duration {
 	case 0:
 		// 00:00:00
 		return 0
-	case 5, 8, 12:
+	case 8, 12:
 		// Properly time length
 	default:
 		panic(mysql.ErrWrongDateLen)
 	}
 	buf := make([]byte, dlen)
 	readFull(rd, buf)
-	tt := int64(0)
-	switch dlen {
-	case 12:
-		// Nanosecond part
-		tt += int64(DecodeU32(buf[8:]))
-		fallthrough
-	case 8:
-		// HH:MM:SS part
-		tt += int64(int(buf[5])*3600+int(buf[6])*60+int(buf[7])) * 1e9
-		fallthrough
-	case 5:
-		// Day part
-		tt += int64(DecodeU32(buf[1:5])) * (24 * 3600 * 1e9)
-		fallthrough
+	// Day part
+	tt := int64(DecodeU32(buf[1:5])) * (24 * 3600 * 1e9)
+	// HH:MM:SS part
+	tt += int64(int(buf[5])*3600+int(buf[6])*60+int(buf[7])) * 1e9
+	if dlen == 12 {
+		// Microsecond part
+		tt += int64(DecodeU32(buf[8:])) * 1e3
 	}
 	if buf[0] != 0 {
 		tt = -tt
@@ -290,29 +284,28 @@ func readDuration(rd io.Reader) time.Duration {
 	return time.Duration(tt)
 }
 
+// Encodes d in binary protocol format. Fractional part is truncated to
+// microseconds (MySQL precision).
 func EncodeDuration(d time.Duration) []byte {
 	buf := make([]byte, 13)
 	if d < 0 {
 		buf[1] = 1
 		d = -d
 	}
-	if ns := uint32(d % 1e9); ns != 0 {
-		copy(buf[9:13], EncodeU32(ns)) // nanosecond
+	if us := uint32(d % 1e9 / 1e3); us != 0 {
+		copy(buf[9:13], EncodeU32(us)) // microsecond
 		buf[0] += 4
 	}
 	d /= 1e9
-	if hms := int(d % (24 * 3600)); buf[0] != 0 || hms != 0 {
+	if buf[0] != 0 || d != 0 {
+		copy(buf[2:6], EncodeU32(uint32(d/(24*3600)))) // day
+		hms := int(d % (24 * 3600))
 		buf[8] = byte(hms % 60) // second
 		hms /= 60
 		buf[7] = byte(hms % 60) // minute
 		buf[6] = byte(hms / 60) // hour
-		buf[0] += 3
-	}
-	if day := uint32(d / (24 * 3600)); buf[0] != 0 || day != 0 {
-		copy(buf[2:6], EncodeU32(day)) // day
-		buf[0] += 4
+		buf[0] += 8
 	}
-	buf[0]++ // For sign byte
 	buf = buf[0 : buf[0]+1]
 	return buf
 }
@@ -322,28 +315,28 @@ func writeDuration(wr io.Writer, d time.Duration) {
 }
 
 func lenDuration(d time.Duration) int {
-	if d == 0 {
-		return 2
+	if d < 0 {
+		d = -d
 	}
-	if d%1e9 != 0 {
+	switch {
+	case d%1e9/1e3 != 0:
 		return 13
-	}
-	d /= 1e9
-	if d%(24*3600) != 0 {
+	case d/1e9 != 0:
 		return 9
 	}
-	return 6
+	return 1
 }
 
-func readTime(rd io.Reader) time.Time {
+// Reads DATE, DATETIME or TIMESTAMP value. Returns all zeros for MySQL zero.
+func readDateTime(rd io.Reader) (y, mon, d, h, m, s, us int) {
 	dlen := readByte(rd)
 	switch dlen {
 	case 251:
 		// Null
 		panic(mysql.ErrUnexpNullDate)
 	case 0:
-		// return 0000-00-00 converted to time.Time zero
-		return time.Time{}
+		// 0000-00-00
+		return
 	case 4, 7, 11:
 		// Properly datetime length
 	default:
@@ -352,11 +345,10 @@ func readTime(rd io.Reader) time.Time {
 
 	buf := make([]byte, dlen)
 	readFull(rd, buf)
-	var y, mon, d, h, m, s, n int
 	switch dlen {
 	case 11:
-		// 2006-01-02 15:04:05.001004005
-		n = int(DecodeU32(buf[7:]))
+		// 2006-01-02 15:04:05.001004
+		us = int(DecodeU32(buf[7:]))
 		fallthrough
 	case 7:
 		// 2006-01-02 15:04:05
@@ -370,14 +362,36 @@ func readTime(rd io.Reader) time.Time {
 		mon = int(buf[2])
 		d = int(buf[3])
 	}
-	return time.Date(y, time.Month(mon), d, h, m, s, n, time.Local)
+	return
+}
+
+// Returns datetime as time.Time in Local location. MySQL zero is returned as
+// mysql.ZeroTime. Datetime with zero month or day (eg. 2014-00-00 00:00:00)
+// can't be represented by time.Time so it is returned as MySQL string in
+// []byte (Row.TimeErr returns mysql.ErrZeroInDate for it).
+func datetimeValue(y, mon, d, h, m, s, us int) interface{} {
+	switch {
+	case y == 0 && mon == 0 && d == 0 && h == 0 && m == 0 && s == 0 && us == 0:
+		return mysql.ZeroTime
+	case mon == 0 || d == 0:
+		str := fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d", y, mon, d, h, m, s)
+		if us != 0 {
+			str += fmt.Sprintf(".%06d", us)
+		}
+		return []byte(str)
+	}
+	return time.Date(y, time.Month(mon), d, h, m, s, us*1e3, time.Local)
+}
+
+func readTime(rd io.Reader) interface{} {
+	return datetimeValue(readDateTime(rd))
 }
 
-func encodeNonzeroTime(y int16, mon, d, h, m, s byte, n uint32) []byte {
+func encodeNonzeroTime(y int16, mon, d, h, m, s byte, us uint32) []byte {
 	buf := make([]byte, 12)
 	switch {
-	case n != 0:
-		copy(buf[7:12], EncodeU32(n))
+	case us != 0:
+		copy(buf[8:12], EncodeU32(us))
 		buf[0] += 4
 		fallthrough
 	case s != 0 || m != 0 || h != 0:
@@ -394,16 +408,18 @@ func encodeNonzeroTime(y int16, mon, d, h, m, s byte, n uint32) []byte {
 	return buf
 }
 
+// Encodes t in binary protocol format. Fractional part is truncated to
+// microseconds (MySQL precision). mysql.ZeroTime is encoded as MySQL zero.
 func EncodeTime(t time.Time) []byte {
-	if t.IsZero() {
+	if mysql.IsZeroTime(t) {
 		return []byte{0} // MySQL zero
 	}
 	y, mon, d := t.Date()
 	h, m, s := t.Clock()
-	n := t.Nanosecond()
+	us := t.Nanosecond() / 1e3
 	return encodeNonzeroTime(
 		int16(y), byte(mon), byte(d),
-		byte(h), byte(m), byte(s), uint32(n),
+		byte(h), byte(m), byte(s), uint32(us),
 	)
 }
 
@@ -413,9 +429,9 @@ func writeTime(wr io.Writer, t time.Time) {
 
 func lenTime(t time.Time) int {
 	switch {
-	case t.IsZero():
+	case mysql.IsZeroTime(t):
 		return 1
-	case t.Nanosecond() != 0:
+	case t.Nanosecond()/1e3 != 0:
 		return 12
 	case t.Second() != 0 || t.Minute() != 0 || t.Hour() != 0:
 		return 8
@@ -424,7 +440,7 @@ func lenTime(t time.Time) int {
 }
 
 func readDate(rd io.Reader) mysql.Date {
-	y, m, d := readTime(rd).Date()
+	y, m, d, _, _, _, _ := readDateTime(rd)
 	return mysql.Date{int16(y), byte(m), byte(d)}
 }
 
@@ -446,30 +462,46 @@ func lenDate(d mysql.Date) int {
 	return 5
 }
 
-func escapeString(txt string) string {
+// Escapes special characters in txt. If mb isn't nil it is used to walk
+// multibyte characters: their trailing bytes are never escaped and lead byte
+// of invalid multibyte character is escaped, so the server can't combine it
+// with following backslash.
+func escapeString(txt string, mb mbCharset) string {
 	var (
 		esc string
 		buf bytes.Buffer
 	)
 	last := 0
-	for ii, bb := range txt {
-		switch bb {
-		case 0:
-			esc = `\0`
-		case '\n':
-			esc = `\n`
-		case '\r':
-			esc = `\r`
-		case '\\':
-			esc = `\\`
-		case '\'':
-			esc = `\'`
-		case '"':
-			esc = `\"`
-		case '\032':
-			esc = `\Z`
-		default:
-			continue
+	for ii := 0; ii < len(txt); ii++ {
+		bb := txt[ii]
+		if bb >= 0x80 && mb != nil {
+			n := mb(txt[ii:])
+			if n > 1 {
+				ii += n - 1
+			}
+			if n >= 0 {
+				continue
+			}
+			esc = `\` + txt[ii:ii+1]
+		} else {
+			switch bb {
+			case 0:
+				esc = `\0`
+			case '\n':
+				esc = `\n`
+			case '\r':
+				esc = `\r`
+			case '\\':
+				esc = `\\`
+			case '\'':
+				esc = `\'`
+			case '"':
+				esc = `\"`
+			case '\032':
+				esc = `\Z`
+			default:
+				continue
+			}
 		}
 		io.WriteString(&buf, txt[last:ii])
 		io.WriteString(&buf, esc)
@@ -479,10 +511,19 @@ func escapeString(txt string) string {
 	return buf.String()
 }
 
-func escapeQuotes(txt string) string {
+// Doubles quotes in txt. If mb isn't nil it is used to skip multibyte
+// characters.
+func escapeQuotes(txt string, mb mbCharset) string {
 	var buf bytes.Buffer
 	last := 0
-	for ii, bb := range txt {
+	for ii := 0; ii < len(txt); ii++ {
+		bb := txt[ii]
+		if bb >= 0x80 && mb != nil {
+			if n := mb(txt[ii:]); n > 1 {
+				ii += n - 1
+			}
+			continue
+		}
 		if bb == '\'' {
 			io.WriteString(&buf, txt[last:ii])
 			io.WriteString(&buf, `''`)
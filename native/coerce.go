@@ -0,0 +1,268 @@
+package native
+
+import (
+	"errors"
+	"github.com/ziutek/mymysql/mysql"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Classes of parameter types used by coercion
+const (
+	_PARAM_ANY = iota // Unknown or text type: any value is accepted
+	_PARAM_INT
+	_PARAM_FLOAT
+	_PARAM_DECIMAL
+	_PARAM_DATETIME
+	_PARAM_TIME
+)
+
+func paramClass(typ byte) int {
+	switch typ {
+	case MYSQL_TYPE_TINY, MYSQL_TYPE_SHORT, MYSQL_TYPE_INT24, MYSQL_TYPE_LONG,
+		MYSQL_TYPE_LONGLONG, MYSQL_TYPE_YEAR:
+		return _PARAM_INT
+	case MYSQL_TYPE_FLOAT, MYSQL_TYPE_DOUBLE:
+		return _PARAM_FLOAT
+	case MYSQL_TYPE_DECIMAL, MYSQL_TYPE_NEWDECIMAL:
+		return _PARAM_DECIMAL
+	case MYSQL_TYPE_DATE, MYSQL_TYPE_NEWDATE, MYSQL_TYPE_DATETIME,
+		MYSQL_TYPE_TIMESTAMP:
+		return _PARAM_DATETIME
+	case MYSQL_TYPE_TIME:
+		return _PARAM_TIME
+	}
+	return _PARAM_ANY
+}
+
+// Sizes of integer parameter types (in bits)
+var intParamBits = map[byte]uint{
+	MYSQL_TYPE_TINY:     8,
+	MYSQL_TYPE_SHORT:    16,
+	MYSQL_TYPE_INT24:    24,
+	MYSQL_TYPE_LONG:     32,
+	MYSQL_TYPE_LONGLONG: 64,
+	MYSQL_TYPE_YEAR:     16,
+}
+
+// Go types used to send integer parameters of given size
+var (
+	intParamTypes = map[uint]reflect.Type{
+		8:  reflect.TypeOf(int8(0)),
+		16: reflect.TypeOf(int16(0)),
+		24: reflect.TypeOf(int32(0)),
+		32: reflect.TypeOf(int32(0)),
+		64: reflect.TypeOf(int64(0)),
+	}
+	uintParamTypes = map[uint]reflect.Type{
+		8:  reflect.TypeOf(uint8(0)),
+		16: reflect.TypeOf(uint16(0)),
+		24: reflect.TypeOf(uint32(0)),
+		32: reflect.TypeOf(uint32(0)),
+		64: reflect.TypeOf(uint64(0)),
+	}
+	float32Type = reflect.TypeOf(float32(0))
+	float64Type = reflect.TypeOf(float64(0))
+)
+
+var (
+	errOutOfRange = errors.New("value out of range")
+	errFraction   = errors.New("value has fractional part")
+)
+
+// Returns true if values of Go type typ can be bound to parameters of class c
+func paramCompatible(c int, typ reflect.Type) bool {
+	if c == _PARAM_ANY || typ == rawType {
+		return true
+	}
+	switch typ {
+	case timeType, dateType, timestampType:
+		return c == _PARAM_DATETIME
+	case durationType:
+		return c == _PARAM_TIME
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Slice:
+		// []byte, Blob
+		return typ.Elem().Kind() == reflect.Uint8
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Float32, reflect.Float64:
+		return c == _PARAM_INT || c == _PARAM_FLOAT || c == _PARAM_DECIMAL
+	case reflect.Bool:
+		return c == _PARAM_INT
+	}
+	return false
+}
+
+// Returns true and text of val if val is a string or []byte value
+func textParam(val reflect.Value) (string, bool) {
+	switch val.Kind() {
+	case reflect.String:
+		return val.String(), true
+	case reflect.Slice:
+		return string(val.Bytes()), true
+	}
+	return "", false
+}
+
+func intParam(val reflect.Value, f *mysql.Field) (reflect.Value, error) {
+	var (
+		i   int64
+		u   uint64
+		isU bool
+		err error
+	)
+	switch val.Kind() {
+	case reflect.Bool:
+		return val, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i = val.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		u, isU = val.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		fl := val.Float()
+		switch {
+		case fl != math.Trunc(fl):
+			return val, errFraction
+		case fl < 0 && fl >= math.MinInt64:
+			i = int64(fl)
+		case fl >= 0 && fl < math.MaxUint64:
+			u, isU = uint64(fl), true
+		default:
+			return val, errOutOfRange
+		}
+	default:
+		str, _ := textParam(val)
+		if i, err = strconv.ParseInt(str, 10, 64); err != nil {
+			// Maybe it is too big for int64
+			var e error
+			if u, e = strconv.ParseUint(str, 10, 64); e != nil {
+				return val, err
+			}
+			isU = true
+		}
+	}
+	bits := intParamBits[f.Type]
+	var out reflect.Value
+	if f.IsUnsigned() {
+		if !isU {
+			if i < 0 {
+				return val, errOutOfRange
+			}
+			u = uint64(i)
+		}
+		if u > math.MaxUint64>>(64-bits) {
+			return val, errOutOfRange
+		}
+		out = reflect.New(uintParamTypes[bits]).Elem()
+		out.SetUint(u)
+	} else {
+		if isU {
+			if u > math.MaxInt64 {
+				return val, errOutOfRange
+			}
+			i = int64(u)
+		}
+		max := int64(math.MaxInt64 >> (64 - bits))
+		if i > max || i < -max-1 {
+			return val, errOutOfRange
+		}
+		out = reflect.New(intParamTypes[bits]).Elem()
+		out.SetInt(i)
+	}
+	return out, nil
+}
+
+func floatParam(val reflect.Value, f *mysql.Field) (reflect.Value, error) {
+	var fl float64
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fl = float64(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		fl = float64(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		fl = val.Float()
+	default:
+		str, _ := textParam(val)
+		var err error
+		if fl, err = strconv.ParseFloat(str, 64); err != nil {
+			return val, err
+		}
+	}
+	typ := float64Type
+	if f.Type == MYSQL_TYPE_FLOAT {
+		if math.Abs(fl) > math.MaxFloat32 {
+			return val, errOutOfRange
+		}
+		typ = float32Type
+	}
+	out := reflect.New(typ).Elem()
+	out.SetFloat(fl)
+	return out, nil
+}
+
+// Checks val against n-th parameter definition f and converts it to the Go
+// type that corresponds to the parameter type. Text values for DECIMAL,
+// DATE/DATETIME/TIMESTAMP and TIME parameters are only checked. If val is
+// bound by pointer (its later modifications are sent during execution) only
+// its type is checked. Panics with *mysql.BindError if val can't be bound.
+func coerceParam(n int, f *mysql.Field, val reflect.Value, bound bool) reflect.Value {
+	if !val.IsValid() {
+		// NULL value
+		return val
+	}
+	typ := val.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+		bound = true
+	}
+	c := paramClass(f.Type)
+	var err error
+	if !paramCompatible(c, typ) {
+		err = errors.New("incompatible type")
+	} else if !bound && typ != rawType {
+		str, isStr := textParam(val)
+		switch c {
+		case _PARAM_INT:
+			val, err = intParam(val, f)
+		case _PARAM_FLOAT:
+			val, err = floatParam(val, f)
+		case _PARAM_DECIMAL:
+			if isStr {
+				_, err = strconv.ParseFloat(str, 64)
+			}
+		case _PARAM_DATETIME:
+			if isStr {
+				_, err = mysql.ParseTime(str, time.UTC)
+			}
+		case _PARAM_TIME:
+			if isStr {
+				_, err = mysql.ParseDuration(str)
+			}
+		}
+	}
+	if err != nil {
+		e := &mysql.BindError{Param: n, Field: f, Msg: err.Error()}
+		if val.CanInterface() {
+			e.Value = val.Interface()
+		}
+		panic(e)
+	}
+	return val
+}
+
+// Binds val to n-th parameter. If coercion is enabled val is checked and
+// converted by coerceParam.
+func (stmt *Stmt) bindParam(n int, val reflect.Value, bound bool) *paramValue {
+	if stmt.coerce && n < len(stmt.param_fields) && stmt.param_fields[n] != nil {
+		val = coerceParam(n, stmt.param_fields[n], val, bound)
+	}
+	return stmt.my.bindValue(val)
+}
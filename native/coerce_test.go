@@ -0,0 +1,135 @@
+package native
+
+import (
+	"bytes"
+	"github.com/ziutek/mymysql/mysql"
+	"testing"
+	"time"
+)
+
+func TestParamFields(t *testing.T) {
+	my := testConn(
+		_CLIENT_PROTOCOL_41, nil,
+		[]byte{0, 7, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0},
+		fieldPkt("?", MYSQL_TYPE_LONGLONG, 63),
+		fieldPkt("?", MYSQL_TYPE_VAR_STRING, 33),
+		[]byte{254, 0, 0, 0, 0},
+	)
+	stmt, err := my.prepare("INSERT t VALUES (?, ?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf := stmt.ParamFields()
+	if len(pf) != 2 || pf[0].Type != MYSQL_TYPE_LONGLONG ||
+		pf[1].Type != MYSQL_TYPE_VAR_STRING || pf[1].Charset != 33 {
+		t.Fatalf("bad parameter fields: %+v", pf)
+	}
+}
+
+type CoerceTest struct {
+	typ   byte
+	flags uint16
+	val   interface{}
+	ptyp  uint16 // Type of bound parameter (0 if error expected)
+	out   []byte // Encoded value
+}
+
+var (
+	coerceInt   = int64(300)
+	coerceTime  = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	coerceTests = []CoerceTest{
+		{MYSQL_TYPE_TINY, 0, 100, MYSQL_TYPE_TINY, []byte{100}},
+		{MYSQL_TYPE_TINY, 0, -128, MYSQL_TYPE_TINY, []byte{0x80}},
+		{MYSQL_TYPE_TINY, 0, 300, 0, nil},
+		{MYSQL_TYPE_TINY, 0, "12", MYSQL_TYPE_TINY, []byte{12}},
+		{MYSQL_TYPE_TINY, 0, "x", 0, nil},
+		{MYSQL_TYPE_TINY, 0, 2.0, MYSQL_TYPE_TINY, []byte{2}},
+		{MYSQL_TYPE_TINY, 0, 2.5, 0, nil},
+		{MYSQL_TYPE_TINY, 0, true, MYSQL_TYPE_TINY, []byte{1}},
+		{MYSQL_TYPE_TINY, 0, coerceTime, 0, nil},
+		{MYSQL_TYPE_TINY, mysql.FLAG_UNSIGNED, 255,
+			MYSQL_TYPE_TINY | MYSQL_UNSIGNED_MASK, []byte{255}},
+		{MYSQL_TYPE_TINY, mysql.FLAG_UNSIGNED, -1, 0, nil},
+		{MYSQL_TYPE_INT24, 0, 1 << 23, 0, nil},
+		{MYSQL_TYPE_LONGLONG, 0, uint64(1 << 63), 0, nil},
+		{MYSQL_TYPE_LONGLONG, mysql.FLAG_UNSIGNED, "18446744073709551615",
+			MYSQL_TYPE_LONGLONG | MYSQL_UNSIGNED_MASK,
+			[]byte{255, 255, 255, 255, 255, 255, 255, 255}},
+		{MYSQL_TYPE_DOUBLE, 0, 3, MYSQL_TYPE_DOUBLE,
+			[]byte{0, 0, 0, 0, 0, 0, 8, 0x40}},
+		{MYSQL_TYPE_FLOAT, 0, "1.5", MYSQL_TYPE_FLOAT,
+			[]byte{0, 0, 0xc0, 0x3f}},
+		{MYSQL_TYPE_DOUBLE, 0, "1,5", 0, nil},
+		{MYSQL_TYPE_NEWDECIMAL, 0, "1.25", MYSQL_TYPE_STRING,
+			[]byte("\x041.25")},
+		{MYSQL_TYPE_NEWDECIMAL, 0, "abc", 0, nil},
+		{MYSQL_TYPE_DATETIME, 0, "2020-01-02 03:04:05", MYSQL_TYPE_STRING,
+			[]byte("\x132020-01-02 03:04:05")},
+		{MYSQL_TYPE_DATE, 0, "2020-13-02", 0, nil},
+		{MYSQL_TYPE_DATETIME, 0, 5, 0, nil},
+		{MYSQL_TYPE_TIME, 0, time.Second, MYSQL_TYPE_TIME,
+			[]byte{8, 0, 0, 0, 0, 0, 0, 0, 1}},
+		{MYSQL_TYPE_TIME, 0, "1:2", 0, nil},
+		{MYSQL_TYPE_VAR_STRING, 0, coerceTime, MYSQL_TYPE_DATETIME, nil},
+		{MYSQL_TYPE_NULL, 0, int64(300), MYSQL_TYPE_LONGLONG, nil},
+		// Bound values: only type is checked
+		{MYSQL_TYPE_TINY, 0, &coerceInt, MYSQL_TYPE_LONGLONG, nil},
+		{MYSQL_TYPE_TINY, 0, &coerceTime, 0, nil},
+	}
+)
+
+func TestCoercion(t *testing.T) {
+	for _, test := range coerceTests {
+		field := &mysql.Field{Type: test.typ, Flags: test.flags}
+		stmt := &Stmt{
+			my:           new(Conn),
+			params:       make([]*paramValue, 1),
+			param_count:  1,
+			param_fields: []*mysql.Field{field},
+			coerce:       true,
+		}
+		err := func() (err error) {
+			defer catchError(&err)
+			stmt.Bind(test.val)
+			return
+		}()
+		if test.ptyp == 0 {
+			if _, ok := err.(*mysql.BindError); !ok {
+				t.Errorf("%s %v: BindError expected: %v",
+					field.DatabaseTypeName(), test.val, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %v: %v", field.DatabaseTypeName(), test.val, err)
+			continue
+		}
+		pv := stmt.params[0]
+		if pv.typ != test.ptyp {
+			t.Errorf("%s %v: bad type 0x%x, expected 0x%x",
+				field.DatabaseTypeName(), test.val, pv.typ, test.ptyp)
+			continue
+		}
+		if test.out != nil {
+			var buf bytes.Buffer
+			pv.Len()
+			writeValue(&buf, pv)
+			if !bytes.Equal(buf.Bytes(), test.out) {
+				t.Errorf("%s %v: bad value %v, expected %v",
+					field.DatabaseTypeName(), test.val, buf.Bytes(), test.out)
+			}
+		}
+	}
+
+	// Without coercion values are bound as is
+	stmt := &Stmt{
+		my:           new(Conn),
+		params:       make([]*paramValue, 1),
+		param_count:  1,
+		param_fields: []*mysql.Field{{Type: MYSQL_TYPE_TINY}},
+	}
+	stmt.Bind(coerceTime)
+	if stmt.params[0].typ != MYSQL_TYPE_DATETIME {
+		t.Fatalf("bad type without coercion: 0x%x", stmt.params[0].typ)
+	}
+}
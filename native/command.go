@@ -20,7 +20,8 @@ func (my *Conn) sendCmd(cmd byte, argv ...interface{}) {
 		writeU16(pw, argv[1].(uint16)) // Parameter number
 		writeBS(pw, argv[2])           // payload
 
-	case _COM_QUIT, _COM_STATISTICS, _COM_PROCESS_INFO, _COM_DEBUG, _COM_PING:
+	case _COM_QUIT, _COM_STATISTICS, _COM_PROCESS_INFO, _COM_DEBUG, _COM_PING,
+		_COM_RESET_CONNECTION:
 		pw := my.newPktWriter(1)
 		writeByte(pw, cmd)
 
@@ -89,6 +90,19 @@ func (my *Conn) sendCmd(cmd byte, argv ...interface{}) {
 			writeBS(pw, argv[3])
 		}
 
+	case _COM_BINLOG_DUMP_GTID:
+		name_len := lenBS(argv[2])
+		data := argv[4].([]byte)
+		pw := my.newPktWriter(1 + 2 + 4 + 4 + name_len + 8 + 4 + len(data))
+		writeByte(pw, cmd)
+		writeU16(pw, argv[0].(uint16))  // Flags
+		writeU32(pw, argv[1].(uint32))  // Slave server id
+		writeU32(pw, uint32(name_len))  // Binlog file name length
+		writeBS(pw, argv[2])            // Binlog file name
+		writeU64(pw, argv[3].(uint64))  // Start position
+		writeU32(pw, uint32(len(data))) // GTID set data length
+		write(pw, data)                 // Encoded GTID set
+
 	// TODO: case COM_REGISTER_SLAVE:
 
 	default:
@@ -1,6 +1,9 @@
 package native
 
-import "strconv"
+import (
+	"github.com/ziutek/mymysql/mysql"
+	"strconv"
+)
 
 // Client caps - borrowed from GoMySQL
 const (
@@ -22,6 +25,25 @@ const (
 	_CLIENT_SECURE_CONN                  // New 4.1 authentication
 	_CLIENT_MULTI_STATEMENTS             // Enable/disable multi-stmt support
 	_CLIENT_MULTI_RESULTS                // Enable/disable multi-results
+	_CLIENT_PS_MULTI_RESULTS             // Multi-results in prepared statements
+	_CLIENT_PLUGIN_AUTH                  // Pluggable authentication
+	_CLIENT_CONNECT_ATTRS                // Connection attributes
+	_CLIENT_PLUGIN_AUTH_LENENC_DATA      // Length encoded auth response
+	_CLIENT_CAN_HANDLE_EXPIRED_PASSWORDS // Sandbox mode for expired passwords
+	_CLIENT_SESSION_TRACK                // Session state changes in OK packets
+	_CLIENT_DEPRECATE_EOF                // OK packets instead of EOF packets
+)
+
+// MariaDB extended caps (sent in last 4 bytes of handshake filler if server
+// doesn't set CLIENT_LONG_PASSWORD)
+const (
+	_MARIADB_CLIENT_PROGRESS             = 1 << iota // Progress reporting
+	_MARIADB_CLIENT_COM_MULTI                        // COM_MULTI command
+	_MARIADB_CLIENT_STMT_BULK_OPERATIONS             // COM_STMT_BULK_EXECUTE
+	_MARIADB_CLIENT_EXTENDED_METADATA                // Extended field metadata
+	_MARIADB_CLIENT_CACHE_METADATA                   // Skip repeated metadata
+	_MARIADB_CLIENT_UNUSED                           // Not used
+	_MARIADB_CLIENT_BULK_UNIT_RESULTS                // Results of bulk units
 )
 
 // Commands - borrowed from GoMySQL
@@ -54,6 +76,65 @@ const (
 	_COM_STMT_RESET          = 0x1a
 	_COM_SET_OPTION          = 0x1b
 	_COM_STMT_FETCH          = 0x1c
+	_COM_BINLOG_DUMP_GTID    = 0x1e
+	_COM_RESET_CONNECTION    = 0x1f
+	_COM_STMT_BULK_EXECUTE   = 0xfa
+)
+
+// COM_BINLOG_DUMP and COM_BINLOG_DUMP_GTID flags
+const (
+	_BINLOG_DUMP_NON_BLOCK   = 0x01
+	_BINLOG_THROUGH_POSITION = 0x02
+	_BINLOG_THROUGH_GTID     = 0x04
+)
+
+// COM_STMT_BULK_EXECUTE flags and parameter indicators
+const (
+	_STMT_BULK_FLAG_SEND_UNIT_RESULTS = 0x40
+	_STMT_BULK_FLAG_SEND_TYPES        = 0x80
+
+	_STMT_INDICATOR_NONE = 0
+	_STMT_INDICATOR_NULL = 1
+)
+
+// Binlog event types
+const (
+	UNKNOWN_EVENT            = 0x00
+	START_EVENT_V3           = 0x01
+	QUERY_EVENT              = 0x02
+	STOP_EVENT               = 0x03
+	ROTATE_EVENT             = 0x04
+	INTVAR_EVENT             = 0x05
+	LOAD_EVENT               = 0x06
+	SLAVE_EVENT              = 0x07
+	CREATE_FILE_EVENT        = 0x08
+	APPEND_BLOCK_EVENT       = 0x09
+	EXEC_LOAD_EVENT          = 0x0a
+	DELETE_FILE_EVENT        = 0x0b
+	NEW_LOAD_EVENT           = 0x0c
+	RAND_EVENT               = 0x0d
+	USER_VAR_EVENT           = 0x0e
+	FORMAT_DESCRIPTION_EVENT = 0x0f
+	XID_EVENT                = 0x10
+	BEGIN_LOAD_QUERY_EVENT   = 0x11
+	EXECUTE_LOAD_QUERY_EVENT = 0x12
+	TABLE_MAP_EVENT          = 0x13
+	WRITE_ROWS_EVENTv0       = 0x14
+	UPDATE_ROWS_EVENTv0      = 0x15
+	DELETE_ROWS_EVENTv0      = 0x16
+	WRITE_ROWS_EVENTv1       = 0x17
+	UPDATE_ROWS_EVENTv1      = 0x18
+	DELETE_ROWS_EVENTv1      = 0x19
+	INCIDENT_EVENT           = 0x1a
+	HEARTBEAT_EVENT          = 0x1b
+	IGNORABLE_EVENT          = 0x1c
+	ROWS_QUERY_EVENT         = 0x1d
+	WRITE_ROWS_EVENTv2       = 0x1e
+	UPDATE_ROWS_EVENTv2      = 0x1f
+	DELETE_ROWS_EVENTv2      = 0x20
+	GTID_EVENT               = 0x21
+	ANONYMOUS_GTID_EVENT     = 0x22
+	PREVIOUS_GTIDS_EVENT     = 0x23
 )
 
 // Server status
@@ -70,6 +151,19 @@ const (
 
 	_SERVER_STATUS_DB_DROPPED           = 0x100
 	_SERVER_STATUS_NO_BACKSLASH_ESCAPES = 0x200
+	_SERVER_PS_OUT_PARAMS               = 0x1000 // Result set of OUT params
+
+	_SERVER_SESSION_STATE_CHANGED = 0x4000 // Session state info in OK packet
+)
+
+// Types of session state information in OK packet
+const (
+	_SESSION_TRACK_SYSTEM_VARIABLES = iota
+	_SESSION_TRACK_SCHEMA
+	_SESSION_TRACK_STATE_CHANGE
+	_SESSION_TRACK_GTIDS
+	_SESSION_TRACK_TRANSACTION_CHARACTERISTICS
+	_SESSION_TRACK_TRANSACTION_STATE
 )
 
 // MySQL protocol types.
@@ -77,33 +171,37 @@ const (
 // mymysql uses only some of them for send data to the MySQL server. Used
 // MySQL types are marked with a comment contains mymysql type that uses it.
 const (
-	MYSQL_TYPE_DECIMAL     = 0x00
-	MYSQL_TYPE_TINY        = 0x01 // int8, uint8, bool
-	MYSQL_TYPE_SHORT       = 0x02 // int16, uint16
-	MYSQL_TYPE_LONG        = 0x03 // int32, uint32
-	MYSQL_TYPE_FLOAT       = 0x04 // float32
-	MYSQL_TYPE_DOUBLE      = 0x05 // float64
-	MYSQL_TYPE_NULL        = 0x06 // nil
-	MYSQL_TYPE_TIMESTAMP   = 0x07 // Timestamp
-	MYSQL_TYPE_LONGLONG    = 0x08 // int64, uint64
-	MYSQL_TYPE_INT24       = 0x09
-	MYSQL_TYPE_DATE        = 0x0a // Date
-	MYSQL_TYPE_TIME        = 0x0b // Time
-	MYSQL_TYPE_DATETIME    = 0x0c // time.Time
-	MYSQL_TYPE_YEAR        = 0x0d
-	MYSQL_TYPE_NEWDATE     = 0x0e
-	MYSQL_TYPE_VARCHAR     = 0x0f
-	MYSQL_TYPE_BIT         = 0x10
-	MYSQL_TYPE_NEWDECIMAL  = 0xf6
-	MYSQL_TYPE_ENUM        = 0xf7
-	MYSQL_TYPE_SET         = 0xf8
-	MYSQL_TYPE_TINY_BLOB   = 0xf9
-	MYSQL_TYPE_MEDIUM_BLOB = 0xfa
-	MYSQL_TYPE_LONG_BLOB   = 0xfb
-	MYSQL_TYPE_BLOB        = 0xfc // Blob
-	MYSQL_TYPE_VAR_STRING  = 0xfd // []byte
-	MYSQL_TYPE_STRING      = 0xfe // string
-	MYSQL_TYPE_GEOMETRY    = 0xff
+	MYSQL_TYPE_DECIMAL     = mysql.MYSQL_TYPE_DECIMAL
+	MYSQL_TYPE_TINY        = mysql.MYSQL_TYPE_TINY      // int8, uint8, bool
+	MYSQL_TYPE_SHORT       = mysql.MYSQL_TYPE_SHORT     // int16, uint16
+	MYSQL_TYPE_LONG        = mysql.MYSQL_TYPE_LONG      // int32, uint32
+	MYSQL_TYPE_FLOAT       = mysql.MYSQL_TYPE_FLOAT     // float32
+	MYSQL_TYPE_DOUBLE      = mysql.MYSQL_TYPE_DOUBLE    // float64
+	MYSQL_TYPE_NULL        = mysql.MYSQL_TYPE_NULL      // nil
+	MYSQL_TYPE_TIMESTAMP   = mysql.MYSQL_TYPE_TIMESTAMP // Timestamp
+	MYSQL_TYPE_LONGLONG    = mysql.MYSQL_TYPE_LONGLONG  // int64, uint64
+	MYSQL_TYPE_INT24       = mysql.MYSQL_TYPE_INT24
+	MYSQL_TYPE_DATE        = mysql.MYSQL_TYPE_DATE     // Date
+	MYSQL_TYPE_TIME        = mysql.MYSQL_TYPE_TIME     // Time
+	MYSQL_TYPE_DATETIME    = mysql.MYSQL_TYPE_DATETIME // time.Time
+	MYSQL_TYPE_YEAR        = mysql.MYSQL_TYPE_YEAR
+	MYSQL_TYPE_NEWDATE     = mysql.MYSQL_TYPE_NEWDATE
+	MYSQL_TYPE_VARCHAR     = mysql.MYSQL_TYPE_VARCHAR
+	MYSQL_TYPE_BIT         = mysql.MYSQL_TYPE_BIT
+	MYSQL_TYPE_TIMESTAMP2  = mysql.MYSQL_TYPE_TIMESTAMP2 // Used only in binlog
+	MYSQL_TYPE_DATETIME2   = mysql.MYSQL_TYPE_DATETIME2  // Used only in binlog
+	MYSQL_TYPE_TIME2       = mysql.MYSQL_TYPE_TIME2      // Used only in binlog
+	MYSQL_TYPE_JSON        = mysql.MYSQL_TYPE_JSON
+	MYSQL_TYPE_NEWDECIMAL  = mysql.MYSQL_TYPE_NEWDECIMAL
+	MYSQL_TYPE_ENUM        = mysql.MYSQL_TYPE_ENUM
+	MYSQL_TYPE_SET         = mysql.MYSQL_TYPE_SET
+	MYSQL_TYPE_TINY_BLOB   = mysql.MYSQL_TYPE_TINY_BLOB
+	MYSQL_TYPE_MEDIUM_BLOB = mysql.MYSQL_TYPE_MEDIUM_BLOB
+	MYSQL_TYPE_LONG_BLOB   = mysql.MYSQL_TYPE_LONG_BLOB
+	MYSQL_TYPE_BLOB        = mysql.MYSQL_TYPE_BLOB       // Blob
+	MYSQL_TYPE_VAR_STRING  = mysql.MYSQL_TYPE_VAR_STRING // []byte
+	MYSQL_TYPE_STRING      = mysql.MYSQL_TYPE_STRING     // string
+	MYSQL_TYPE_GEOMETRY    = mysql.MYSQL_TYPE_GEOMETRY
 
 	MYSQL_UNSIGNED_MASK = uint16(1 << 15)
 )
@@ -150,27 +248,28 @@ const (
 	IN_MEDIUMTEXT = MYSQL_TYPE_MEDIUM_BLOB // []byte
 	IN_LONGBLOB   = MYSQL_TYPE_LONG_BLOB   // []byte
 	IN_LONGTEXT   = MYSQL_TYPE_LONG_BLOB   // []byte
+	IN_JSON       = MYSQL_TYPE_JSON        // []byte
 
 	// MySQL 5.x specific
 	IN_DECIMAL = MYSQL_TYPE_NEWDECIMAL // TODO
 	IN_BIT     = MYSQL_TYPE_BIT        // []byte
 )
 
-// Flags - borrowed from GoMySQL
+// Flags
 const (
-	_FLAG_NOT_NULL = 1 << iota
-	_FLAG_PRI_KEY
-	_FLAG_UNIQUE_KEY
-	_FLAG_MULTIPLE_KEY
-	_FLAG_BLOB
-	_FLAG_UNSIGNED
-	_FLAG_ZEROFILL
-	_FLAG_BINARY
-	_FLAG_ENUM
-	_FLAG_AUTO_INCREMENT
-	_FLAG_TIMESTAMP
-	_FLAG_SET
-	_FLAG_NO_DEFAULT_VALUE
+	_FLAG_NOT_NULL         = mysql.FLAG_NOT_NULL
+	_FLAG_PRI_KEY          = mysql.FLAG_PRI_KEY
+	_FLAG_UNIQUE_KEY       = mysql.FLAG_UNIQUE_KEY
+	_FLAG_MULTIPLE_KEY     = mysql.FLAG_MULTIPLE_KEY
+	_FLAG_BLOB             = mysql.FLAG_BLOB
+	_FLAG_UNSIGNED         = mysql.FLAG_UNSIGNED
+	_FLAG_ZEROFILL         = mysql.FLAG_ZEROFILL
+	_FLAG_BINARY           = mysql.FLAG_BINARY
+	_FLAG_ENUM             = mysql.FLAG_ENUM
+	_FLAG_AUTO_INCREMENT   = mysql.FLAG_AUTO_INCREMENT
+	_FLAG_TIMESTAMP        = mysql.FLAG_TIMESTAMP
+	_FLAG_SET              = mysql.FLAG_SET
+	_FLAG_NO_DEFAULT_VALUE = mysql.FLAG_NO_DEFAULT_VALUE
 )
 
 var (
@@ -18,17 +18,25 @@ func (my *Conn) init() {
 	my.info.thr_id = readU32(pr)
 	readFull(pr, my.info.scramble[0:8])
 	read(pr, 1)
-	my.info.caps = readU16(pr)
+	my.info.caps = uint32(readU16(pr))
 	my.info.lang = readByte(pr)
 	my.status = readU16(pr)
-	read(pr, 13)
+	my.info.caps |= uint32(readU16(pr)) << 16
+	read(pr, 7)
+	if my.info.caps&_CLIENT_LONG_PASSWORD == 0 {
+		// MariaDB sends its extended capabilities in last 4 reserved bytes
+		my.info.ext_caps = readU32(pr)
+	} else {
+		my.info.ext_caps = 0
+		read(pr, 4)
+	}
 	if my.info.caps&_CLIENT_PROTOCOL_41 != 0 {
 		readFull(pr, my.info.scramble[8:])
 	}
 	pr.readAll() // Skip other information
 	if my.Debug {
-		log.Printf(tab8s+"ProtVer=%d, ServVer=\"%s\" Status=0x%x",
-			my.info.prot_ver, my.info.serv_ver, my.status,
+		log.Printf(tab8s+"ProtVer=%d, ServVer=\"%s\" Caps=0x%x Status=0x%x",
+			my.info.prot_ver, my.info.serv_ver, my.info.caps, my.status,
 		)
 	}
 	if my.info.caps&_CLIENT_PROTOCOL_41 == 0 {
@@ -36,7 +44,9 @@ func (my *Conn) init() {
 	}
 }
 
-func (my *Conn) auth() {
+// Sends authentication packet. Returns SET NAMES query that should be
+// executed after authentication if configured collation can't be sent in it.
+func (my *Conn) auth() (set_names string) {
 	if my.Debug {
 		log.Printf("[%2d <-] Authentication packet", my.seq)
 	}
@@ -48,25 +58,46 @@ func (my *Conn) auth() {
 			_CLIENT_SECURE_CONN |
 			_CLIENT_LOCAL_FILES |
 			_CLIENT_MULTI_STATEMENTS |
-			_CLIENT_MULTI_RESULTS)
+			_CLIENT_MULTI_RESULTS |
+			_CLIENT_PS_MULTI_RESULTS |
+			_CLIENT_CONNECT_ATTRS |
+			_CLIENT_SESSION_TRACK |
+			_CLIENT_DEPRECATE_EOF)
 	// Reset flags not supported by server
-	flags &= uint32(my.info.caps) | 0xffff0000
+	flags &= my.info.caps
+	coll, set_names := my.handshakeCollation()
+	my.collation = coll
 	scrPasswd := encryptedPasswd(my.passwd, my.info.scramble)
 	pay_len := 4 + 4 + 1 + 23 + len(my.user) + 1 + 1 + len(scrPasswd)
 	if len(my.dbname) > 0 {
 		pay_len += len(my.dbname) + 1
 		flags |= _CLIENT_CONNECT_WITH_DB
 	}
+	var attrs []byte
+	if flags&_CLIENT_CONNECT_ATTRS != 0 {
+		attrs = my.encodedAttrs()
+		pay_len += lenBin(attrs)
+	}
+	my.caps = flags
+	my.ext_caps = 0
+	if flags&_CLIENT_LONG_PASSWORD == 0 {
+		my.ext_caps = my.info.ext_caps & (_MARIADB_CLIENT_STMT_BULK_OPERATIONS |
+			_MARIADB_CLIENT_BULK_UNIT_RESULTS)
+	}
 	pw := my.newPktWriter(pay_len)
 	writeU32(pw, flags)
 	writeU32(pw, uint32(my.max_pkt_size))
-	writeByte(pw, my.info.lang) // Charset number
-	write(pw, make([]byte, 23)) // Filler
+	writeByte(pw, byte(coll))   // Charset number
+	write(pw, make([]byte, 19)) // Filler
+	writeU32(pw, my.ext_caps)   // MariaDB extended capabilities
 	writeNTS(pw, my.user)       // Username
 	writeBin(pw, scrPasswd)     // Encrypted password
 	if len(my.dbname) > 0 {
 		writeNTS(pw, my.dbname)
 	}
+	if flags&_CLIENT_CONNECT_ATTRS != 0 {
+		writeBin(pw, attrs) // Connection attributes (may be empty)
+	}
 	if len(my.dbname) > 0 {
 		pay_len += len(my.dbname) + 1
 		flags |= _CLIENT_CONNECT_WITH_DB
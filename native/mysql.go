@@ -16,8 +16,9 @@ type serverInfo struct {
 	serv_ver string
 	thr_id   uint32
 	scramble []byte
-	caps     uint16
+	caps     uint32
 	lang     byte
+	ext_caps uint32 // MariaDB extended capabilities
 }
 
 // MySQL connection handler
@@ -39,9 +40,19 @@ type Conn struct {
 
 	unreaded_reply bool
 
+	caps     uint32 // Capabilities negotiated with server
+	ext_caps uint32 // MariaDB extended capabilities negotiated with server
+
+	attrs map[string]string // Connection attributes sent to the server
+
+	charset   string // Character set or collation requested by user
+	collation uint16 // Id of the collation used by connection
+
 	init_cmds []string         // MySQL commands/queries executed after connect
 	stmt_map  map[uint32]*Stmt // For reprepare during reconnect
 
+	stmt_cache stmtCache // Statements prepared by PrepareCached
+
 	// Current status of MySQL server connection
 	status uint16
 
@@ -49,6 +60,18 @@ type Conn struct {
 	// Default 16*1024*1024-1. You may change it before connect.
 	max_pkt_size int
 
+	// Convert values in text protocol rows to types used by binary protocol
+	typed_text bool
+
+	// Convert strings between UTF-8 and single byte character sets
+	transcode bool
+
+	// Session state changes reported by server since connect
+	session *mysql.SessionState
+
+	// Called after automatic reprepare of a statement
+	reprepare_handler func(stmt *Stmt, cause error)
+
 	// Debug logging. You may change it at any time.
 	Debug bool
 }
@@ -65,6 +88,8 @@ func New(proto, laddr, raddr, user, passwd string, db ...string) mysql.Conn {
 		passwd:       passwd,
 		stmt_map:     make(map[uint32]*Stmt),
 		max_pkt_size: 16*1024*1024 - 1,
+		attrs:        defaultAttrs(),
+		stmt_cache:   stmtCache{size: 32},
 	}
 	if len(db) == 1 {
 		my.dbname = db[0]
@@ -84,6 +109,15 @@ func (my *Conn) Clone() mysql.Conn {
 		c = New(my.proto, my.laddr, my.raddr, my.user, my.passwd, my.dbname).(*Conn)
 	}
 	c.max_pkt_size = my.max_pkt_size
+	c.stmt_cache.size = my.stmt_cache.size
+	c.charset = my.charset
+	c.attrs = make(map[string]string, len(my.attrs))
+	for name, value := range my.attrs {
+		c.attrs[name] = value
+	}
+	c.typed_text = my.typed_text
+	c.transcode = my.transcode
+	c.reprepare_handler = my.reprepare_handler
 	c.Debug = my.Debug
 	return c
 }
@@ -97,6 +131,18 @@ func (my *Conn) SetMaxPktSize(new_size int) int {
 	return old_size
 }
 
+// Enables/disables conversion of values in rows returned by text queries
+// (Query, Start) to the same types that are used for results of prepared
+// statements (intX/uintX, floatX, Date, time.Time, time.Duration, []byte),
+// so both ways yield identical rows. Values that can't be converted are left
+// as []byte. Affects results obtained after the call. See also
+// Result.SetTypedText. Returns old value.
+func (my *Conn) SetTypedText(on bool) bool {
+	old := my.typed_text
+	my.typed_text = on
+	return old
+}
+
 func (my *Conn) connect() (err error) {
 	defer catchError(&err)
 
@@ -152,7 +198,7 @@ func (my *Conn) connect() (err error) {
 
 	// Initialisation
 	my.init()
-	my.auth()
+	set_names := my.auth()
 	res := my.getResult(nil, nil)
 	if res == nil {
 		// Try old password
@@ -164,11 +210,29 @@ func (my *Conn) connect() (err error) {
 	}
 
 	// Execute all registered commands
-	for _, cmd := range my.init_cmds {
+	cmds := my.init_cmds
+	if set_names != "" {
+		// Connection fails if this first command fails
+		cmds = append([]string{set_names}, cmds...)
+		my.collation, _ = mysql.CollationId(my.charset)
+	}
+	if err = my.execCmds(cmds); err != nil {
+		return
+	}
+
+	// Session state after initialisation is the reference state
+	my.session = nil
+	return
+}
+
+// Executes commands and discards their results.
+func (my *Conn) execCmds(cmds []string) (err error) {
+	for _, cmd := range cmds {
 		// Send command
 		my.sendCmd(_COM_QUERY, cmd)
 		// Get command response
 		res := my.getResponse()
+		my.trackCharset(cmd)
 
 		if res.StatusOnly() {
 			// No fields in result (OK result)
@@ -194,7 +258,6 @@ func (my *Conn) connect() (err error) {
 			}
 		}
 	}
-
 	return
 }
 
@@ -240,18 +303,24 @@ func (my *Conn) Close() (err error) {
 }
 
 // Close and reopen connection.
-// Ignore unreaded rows, reprepare all prepared statements.
+// Ignore unreaded rows, reprepare all prepared statements (except statements
+// cached by PrepareCached, which are discarded).
 func (my *Conn) Reconnect() (err error) {
 	if my.net_conn != nil {
 		// Close connection, ignore all errors
 		my.closeConn()
 	}
+	my.dropCachedStmts()
 	// Reopen the connection.
 	if err = my.connect(); err != nil {
 		return
 	}
 
-	// Reprepare all prepared statements
+	return my.reprepare()
+}
+
+// Reprepares all prepared statements
+func (my *Conn) reprepare() (err error) {
 	var (
 		new_stmt *Stmt
 		new_map  = make(map[uint32]*Stmt)
@@ -264,6 +333,7 @@ func (my *Conn) Reconnect() (err error) {
 		// Assume that fields set in new_stmt by prepare() are indentical to
 		// corresponding fields in stmt. Why can they be different?
 		stmt.id = new_stmt.id
+		stmt.param_fields = new_stmt.param_fields
 		stmt.rebind = true
 		new_map[stmt.id] = stmt
 	}
@@ -273,6 +343,51 @@ func (my *Conn) Reconnect() (err error) {
 	return
 }
 
+// Resets session state (temporary tables, user variables, transaction,
+// session variables, server side prepared statements) without closing the
+// connection. Uses COM_RESET_CONNECTION if the server supports it, otherwise
+// COM_CHANGE_USER (reauthentication with the same credentials). Next
+// restores connection character set, executes registered initialisation
+// commands and reprepares all prepared statements (statements cached by
+// PrepareCached are discarded).
+func (my *Conn) ResetSession() (err error) {
+	defer catchError(&err)
+
+	if my.net_conn == nil {
+		return mysql.ErrNotConn
+	}
+	if my.unreaded_reply {
+		return mysql.ErrUnreadedReply
+	}
+
+	if my.ServerInfo().SupportsResetConnection() {
+		my.sendCmd(_COM_RESET_CONNECTION)
+		my.getResult(nil, nil)
+	} else {
+		my.sendCmd(
+			_COM_CHANGE_USER,
+			my.user, encryptedPasswd(my.passwd, my.info.scramble), my.dbname,
+		)
+		if my.getResult(nil, nil) == nil {
+			// Try old password
+			my.oldPasswd()
+			if my.getResult(nil, nil) == nil {
+				return mysql.ErrAuthentication
+			}
+		}
+	}
+	my.dropCachedStmts()
+	cmds := my.init_cmds
+	if my.collation != 0 {
+		cmds = append([]string{setNamesQuery(my.collation)}, cmds...)
+	}
+	if err = my.execCmds(cmds); err != nil {
+		return
+	}
+	my.session = nil
+	return my.reprepare()
+}
+
 // Change database
 func (my *Conn) Use(dbname string) (err error) {
 	defer catchError(&err)
@@ -290,6 +405,8 @@ func (my *Conn) Use(dbname string) (err error) {
 	my.getResult(nil, nil)
 	// Save new database name if no errors
 	my.dbname = dbname
+	// Cached statements refer to tables in the previous database
+	my.deleteCachedStmts()
 
 	return
 }
@@ -322,10 +439,11 @@ func (my *Conn) Start(sql string, params ...interface{}) (res mysql.Result, err
 		sql = fmt.Sprintf(sql, params...)
 	}
 	// Send query
-	my.sendCmd(_COM_QUERY, sql)
+	my.sendCmd(_COM_QUERY, my.encodeQuery(sql))
 
 	// Get command response
 	res = my.getResponse()
+	my.trackCharset(sql)
 	return
 }
 
@@ -420,7 +538,7 @@ func (my *Conn) prepare(sql string) (stmt *Stmt, err error) {
 	defer catchError(&err)
 
 	// Send command
-	my.sendCmd(_COM_STMT_PREPARE, sql)
+	my.sendCmd(_COM_STMT_PREPARE, my.encodeQuery(sql))
 	// Get server response
 	stmt, ok := my.getPrepareResult(nil).(*Stmt)
 	if !ok {
@@ -465,7 +583,11 @@ func (my *Conn) Prepare(sql string) (mysql.Stmt, error) {
 // A struct field can by value or pointer to value. A parameter (slice element)
 // can be value, pointer to value or pointer to pointer to value.
 // Values may be of the folowind types: intXX, uintXX, floatXX, bool, []byte,
-// Blob, string, Time, Date, Time, Timestamp, Raw.
+// Blob, string, Time, Date, Time, Timestamp, Raw. Values of other types that
+// implement json.Marshaler are sent as JSON documents (json.RawMessage is
+// sent as is). Geometries (geo.Value and types from mysql/geo package) are
+// sent in MySQL internal format. If coercion is enabled (see SetCoercion)
+// values are checked against types of parameters reported by the server.
 func (stmt *Stmt) Bind(params ...interface{}) {
 	stmt.rebind = true
 
@@ -473,7 +595,8 @@ func (stmt *Stmt) Bind(params ...interface{}) {
 	if len(params) == 1 {
 		pval := reflect.ValueOf(params[0])
 		kind := pval.Kind()
-		if kind == reflect.Ptr {
+		bound := kind == reflect.Ptr
+		if bound {
 			// Dereference pointer
 			pval = pval.Elem()
 			kind = pval.Kind()
@@ -483,7 +606,9 @@ func (stmt *Stmt) Bind(params ...interface{}) {
 			typ != timeType &&
 			typ != dateType &&
 			typ != timestampType &&
-			typ != rawType {
+			typ != rawType &&
+			!isGeometry(typ) &&
+			!isJsonMarshaler(typ) {
 			// We have struct to bind
 			if pval.NumField() != stmt.param_count {
 				panic(mysql.ErrBindCount)
@@ -495,7 +620,7 @@ func (stmt *Stmt) Bind(params ...interface{}) {
 				pval = v
 			}
 			for ii := 0; ii < stmt.param_count; ii++ {
-				stmt.params[ii] = bindValue(pval.Field(ii))
+				stmt.params[ii] = stmt.bindParam(ii, pval.Field(ii), bound)
 			}
 			stmt.binded = true
 			return
@@ -510,10 +635,12 @@ func (stmt *Stmt) Bind(params ...interface{}) {
 	}
 	for ii, par := range params {
 		pval := reflect.ValueOf(par)
+		bound := false
 		if pval.IsValid() {
 			if pval.Kind() == reflect.Ptr {
 				// Dereference pointer - this value i addressable
 				pval = pval.Elem()
+				bound = true
 			} else {
 				// Make an addressable value
 				v := reflect.New(pval.Type()).Elem()
@@ -521,7 +648,7 @@ func (stmt *Stmt) Bind(params ...interface{}) {
 				pval = v
 			}
 		}
-		stmt.params[ii] = bindValue(pval)
+		stmt.params[ii] = stmt.bindParam(ii, pval, bound)
 	}
 	stmt.binded = true
 }
@@ -536,8 +663,11 @@ func (stmt *Stmt) ResetParams() {
 
 // Execute prepared statement. If statement requires parameters you may bind
 // them first or specify directly. After this command you may use GetRow to
-// retrieve data.
+// retrieve data. If the server reports that the statement has to be
+// reprepared (ER_NEED_REPREPARE, ER_UNKNOWN_STMT_HANDLER) it is reprepared and
+// executed again (see SetReprepareHandler).
 func (stmt *Stmt) Run(params ...interface{}) (res mysql.Result, err error) {
+	defer stmt.uncacheStale(&err)
 	defer catchError(&err)
 
 	if stmt.my.net_conn == nil {
@@ -554,10 +684,8 @@ func (stmt *Stmt) Run(params ...interface{}) (res mysql.Result, err error) {
 		panic(mysql.ErrBindCount)
 	}
 
-	// Send EXEC command with binded parameters
-	stmt.sendCmdExec()
-	// Get response
-	r := stmt.my.getResponse()
+	// Send EXEC command with binded parameters and get response
+	r := stmt.execute()
 	r.binary = true
 	res = r
 	return
@@ -578,8 +706,9 @@ func (stmt *Stmt) Delete() (err error) {
 	// Allways delete statement on client side, even if
 	// the command return an error.
 	defer func() {
-		// Delete statement from stmt_map
+		// Delete statement from stmt_map and the statement cache
 		delete(stmt.my.stmt_map, stmt.id)
+		stmt.my.stmt_cache.remove(stmt)
 		// Invalidate handler
 		*stmt = Stmt{}
 	}()
@@ -755,45 +884,100 @@ func (res *Result) GetRows() ([]mysql.Row, error) {
 }
 
 // Escapes special characters in the txt, so it is safe to place returned string
-// to Query method.
+// to Query method. Multibyte characters of connection character set are
+// handled properly.
 func (my *Conn) EscapeString(txt string) string {
 	if my.status&_SERVER_STATUS_NO_BACKSLASH_ESCAPES != 0 {
-		return escapeQuotes(txt)
+		return escapeQuotes(txt, my.mbCharset())
 	}
-	return escapeString(txt)
+	return escapeString(txt, my.mbCharset())
 }
 
 type Transaction struct {
 	*Conn
+
+	// Top level transaction and savepoint used by nested transaction (nil
+	// and "" for top level one)
+	root      *Transaction
+	savepoint string
+
+	savepoints []string // Savepoints of top level transaction (oldest first)
+	nested     int      // Number of nested transactions started
 }
 
 // Starts a new transaction
 func (my *Conn) Begin() (mysql.Transaction, error) {
 	_, err := my.Start("START TRANSACTION")
-	return &Transaction{my}, err
+	return &Transaction{Conn: my}, err
 }
 
-// Commit a transaction
-func (tr Transaction) Commit() error {
+// Starts a new transaction with options opts. Isolation level is set for
+// this transaction only (using SET TRANSACTION before START TRANSACTION).
+// Returns mysql.ErrTxOptions for invalid combination of options and
+// mysql.ErrNotSupported if server doesn't support READ ONLY / READ WRITE.
+func (my *Conn) BeginTx(opts mysql.TxOptions) (mysql.Transaction, error) {
+	stmts, err := opts.Statements()
+	if err != nil {
+		return nil, err
+	}
+	if (opts.ReadOnly || opts.ReadWrite) &&
+		!my.ServerInfo().SupportsTxAccessMode() {
+		return nil, mysql.ErrNotSupported
+	}
+	for _, sql := range stmts {
+		if _, err = my.Start(sql); err != nil {
+			return nil, err
+		}
+	}
+	return &Transaction{Conn: my}, nil
+}
+
+// Commit a transaction. Commit of nested transaction releases its savepoint.
+func (tr *Transaction) Commit() error {
+	if tr.root != nil {
+		err := tr.root.release(tr.savepoint)
+		tr.Conn = nil // Invalidate this transaction
+		return err
+	}
 	_, err := tr.Start("COMMIT")
+	tr.savepoints = nil
 	tr.Conn = nil // Invalidate this transaction
 	return err
 }
 
-// Rollback a transaction
-func (tr Transaction) Rollback() error {
+// Rollback a transaction. Rollback of nested transaction rolls back the
+// top level transaction to its savepoint and releases the savepoint.
+func (tr *Transaction) Rollback() error {
+	if tr.root != nil {
+		err := tr.root.rollbackTo(tr.savepoint)
+		if err == nil {
+			err = tr.root.release(tr.savepoint)
+		}
+		tr.Conn = nil // Invalidate this transaction
+		return err
+	}
 	_, err := tr.Start("ROLLBACK")
+	tr.savepoints = nil
 	tr.Conn = nil // Invalidate this transaction
 	return err
 }
 
-func (tr Transaction) IsValid() bool {
-	return tr.Conn != nil
+// Returns false after Commit or Rollback. Nested transaction is also invalid
+// if its savepoint doesn't exist (eg. the top level transaction was rolled
+// back to an earlier savepoint).
+func (tr *Transaction) IsValid() bool {
+	if tr.Conn == nil {
+		return false
+	}
+	if tr.root == nil {
+		return true
+	}
+	return tr.root.IsValid() && tr.root.findSavepoint(tr.savepoint) >= 0
 }
 
 // Binds statement to the context of transaction. For native engine this is
 // identity function.
-func (tr Transaction) Do(st mysql.Stmt) mysql.Stmt {
+func (tr *Transaction) Do(st mysql.Stmt) mysql.Stmt {
 	if s, ok := st.(*Stmt); !ok || s.my != tr.Conn {
 		panic("Transaction and statement doesn't belong to the same connection")
 	}
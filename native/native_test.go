@@ -200,6 +200,7 @@ func TestQuery(t *testing.T) {
 					Name:     "Str",
 					OrgName:  "s",
 					DispLen:  3 * 40, //varchar(40)
+					Charset:  33,     // utf8_general_ci
 					Flags:    0,
 					Type:     MYSQL_TYPE_VAR_STRING,
 					Scale:    0,
@@ -284,6 +285,7 @@ func TestPrepared(t *testing.T) {
 				Name:    "i",
 				OrgName: "ii",
 				DispLen: 11,
+				Charset: mysql.BINARY_COLLATION,
 				Flags:   _FLAG_NO_DEFAULT_VALUE | _FLAG_NOT_NULL,
 				Type:    MYSQL_TYPE_LONG,
 				Scale:   0,
@@ -293,6 +295,7 @@ func TestPrepared(t *testing.T) {
 				Name:    "s",
 				OrgName: "ss",
 				DispLen: 3 * 20, // varchar(20)
+				Charset: 33,     // utf8_general_ci
 				Flags:   0,
 				Type:    MYSQL_TYPE_VAR_STRING,
 				Scale:   0,
@@ -302,6 +305,7 @@ func TestPrepared(t *testing.T) {
 				Name:    "d",
 				OrgName: "dd",
 				DispLen: 19,
+				Charset: mysql.BINARY_COLLATION,
 				Flags:   _FLAG_BINARY,
 				Type:    MYSQL_TYPE_DATETIME,
 				Scale:   0,
@@ -327,7 +331,7 @@ func TestPrepared(t *testing.T) {
 	checkErr(t, err, nil)
 	parsedZero, err := mysql.ParseTime("0000-00-00 00:00:00", time.Local)
 	checkErr(t, err, nil)
-	if !parsedZero.IsZero() {
+	if !mysql.IsZeroTime(parsedZero) {
 		t.Fatalf("time '%s' isn't zero", parsedZero)
 	}
 	exp_rows := []mysql.Row{
@@ -0,0 +1,167 @@
+package native
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/ziutek/mymysql/mysql"
+)
+
+type pipelineCmd struct {
+	sql    string // Text query (empty for prepared statement)
+	seq    byte   // Sequence number after the command was written
+	binary bool   // Prepared statement execution
+}
+
+// Queue of commands (text queries and prepared statement executions) that
+// are sent to the server in one write.
+type Pipeline struct {
+	my   *Conn
+	buf  bytes.Buffer
+	wr   *bufio.Writer
+	cmds []pipelineCmd
+}
+
+// Returns new empty pipeline of commands for this connection.
+func (my *Conn) Pipeline() mysql.Pipeline {
+	p := &Pipeline{my: my}
+	p.wr = bufio.NewWriter(&p.buf)
+	return p
+}
+
+// Encodes command written by send into the pipeline buffer
+func (p *Pipeline) queue(send func()) (err error) {
+	defer catchError(&err)
+
+	my := p.my
+	wr, n := my.wr, p.buf.Len()
+	my.wr = p.wr
+	defer func() {
+		my.wr = wr
+		if err != nil {
+			// Discard partially written command
+			p.wr.Reset(&p.buf)
+			p.buf.Truncate(n)
+		}
+	}()
+	send()
+	return
+}
+
+// Adds text query to the pipeline. If you specify the parameters, the SQL
+// string will be a result of fmt.Sprintf(sql, params...).
+func (p *Pipeline) Query(sql string, params ...interface{}) error {
+	if len(params) != 0 {
+		sql = fmt.Sprintf(sql, params...)
+	}
+	return p.queue(func() {
+		p.my.sendCmd(_COM_QUERY, p.my.encodeQuery(sql))
+		p.cmds = append(p.cmds, pipelineCmd{sql: sql, seq: p.my.seq})
+	})
+}
+
+// Adds execution of prepared statement to the pipeline. Parameters (if any)
+// are binded and encoded immediately.
+func (p *Pipeline) Exec(st mysql.Stmt, params ...interface{}) error {
+	stmt, ok := st.(*Stmt)
+	if !ok || stmt.my != p.my {
+		return mysql.ErrBadCommand
+	}
+	return p.queue(func() {
+		if len(params) != 0 {
+			stmt.Bind(params...)
+		} else if stmt.param_count != 0 && !stmt.binded {
+			panic(mysql.ErrBindCount)
+		}
+		stmt.sendCmdExec()
+		// The pipeline may be never run, so send parameter types again
+		stmt.rebind = true
+		p.cmds = append(p.cmds, pipelineCmd{seq: p.my.seq, binary: true})
+	})
+}
+
+// Returns number of commands in the pipeline.
+func (p *Pipeline) Len() int {
+	return len(p.cmds)
+}
+
+// Reads response to cmd with all its rows. Additional result sets (procedure
+// calls, multi statements) are read and discarded.
+func (p *Pipeline) result(cmd pipelineCmd) (r mysql.PipelineResult) {
+	defer catchError(&r.Err)
+
+	my := p.my
+	my.seq = cmd.seq
+	res := my.getResponse()
+	res.binary = cmd.binary
+	r.Res = res
+	if r.Rows, r.Err = mysql.GetRows(res); r.Err != nil {
+		return
+	}
+	for res.MoreResults() {
+		res = my.getResponse()
+		res.binary = cmd.binary
+		if _, r.Err = mysql.GetRows(res); r.Err != nil {
+			return
+		}
+	}
+	if !cmd.binary {
+		my.trackCharset(cmd.sql)
+	}
+	return
+}
+
+// Sends all commands to the server in one write and reads their responses.
+// Returns results in order of commands. Errors returned by the server for a
+// command are stored in its result and don't affect other commands. Other
+// errors (eg. network errors) stop reading of responses and are returned
+// with results read so far. Responses to the remaining commands can't be
+// read after such error, so the connection is closed (use Reconnect to
+// reopen it). The pipeline is empty after Run.
+func (p *Pipeline) Run() (results []mysql.PipelineResult, err error) {
+	my := p.my
+	if my.net_conn == nil {
+		return nil, mysql.ErrNotConn
+	}
+	if my.unreaded_reply {
+		return nil, mysql.ErrUnreadedReply
+	}
+	cmds := p.cmds
+	p.cmds = nil
+	if err = p.wr.Flush(); err != nil {
+		p.wr.Reset(&p.buf)
+		p.buf.Reset()
+		return
+	}
+	// Write commands concurrently with reading responses, so the server
+	// can't be blocked by unread responses while we are writing.
+	werr := make(chan error, 1)
+	go func(buf []byte) {
+		if _, err := my.wr.Write(buf); err != nil {
+			werr <- err
+			return
+		}
+		werr <- my.wr.Flush()
+	}(p.buf.Bytes())
+
+	results = make([]mysql.PipelineResult, 0, len(cmds))
+	for _, cmd := range cmds {
+		r := p.result(cmd)
+		if _, ok := r.Err.(*mysql.Error); r.Err != nil && !ok {
+			err = r.Err
+			break
+		}
+		results = append(results, r)
+	}
+	if err != nil {
+		// Closing the connection also stops the writer if it is blocked
+		my.net_conn.Close()
+		my.net_conn = nil // Mark that we disconnect
+		my.unreaded_reply = false
+	}
+	if e := <-werr; err == nil {
+		err = e
+	}
+	p.buf.Reset()
+	return
+}
@@ -0,0 +1,114 @@
+package native
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/ziutek/mymysql/mysql"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestPipeline(t *testing.T) {
+	var out bytes.Buffer
+	my := testConn(
+		0, &out,
+		okPkt, nil,
+		errPkt, nil,
+		[]byte{1}, fieldPkt("a", MYSQL_TYPE_VAR_STRING, 33), eofPkt,
+		[]byte{1, 'x'}, eofPkt, nil,
+		okPkt,
+	)
+	stmt := &Stmt{
+		my:          my,
+		id:          5,
+		params:      make([]*paramValue, 1),
+		param_count: 1,
+	}
+	p := my.Pipeline()
+	if err := p.Query("INSERT t VALUES (%d)", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Query("BAD"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Query("SELECT a FROM t"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Exec(stmt, 1, 2); err != mysql.ErrBindCount {
+		t.Fatalf("Exec returned %v", err)
+	}
+	if err := p.Exec(stmt, int8(7)); err != nil {
+		t.Fatal(err)
+	}
+	if p.Len() != 4 || out.Len() != 0 {
+		t.Fatalf("bad pipeline state: len=%d out=%q", p.Len(), out.Bytes())
+	}
+
+	results, err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 || p.Len() != 0 {
+		t.Fatalf("bad number of results: %d", len(results))
+	}
+	for ii, r := range results {
+		if ii == 1 {
+			if e, ok := r.Err.(*mysql.Error); !ok || e.Code != mysql.ER_NO_SUCH_THREAD {
+				t.Fatalf("result %d: bad error: %v", ii, r.Err)
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Fatalf("result %d: %v", ii, r.Err)
+		}
+	}
+	if len(results[2].Rows) != 1 || results[2].Rows[0].Str(0) != "x" {
+		t.Fatalf("bad rows: %v", results[2].Rows)
+	}
+	if my.unreaded_reply {
+		t.Fatal("unreaded reply after Run")
+	}
+
+	exp := []string{
+		"\x03INSERT t VALUES (1)",
+		"\x03BAD",
+		"\x03SELECT a FROM t",
+		"\x17\x05\x00\x00\x00\x00\x01\x00\x00\x00\x00\x01\x01\x00\x07",
+	}
+	pkts := cmdPkts(&out)
+	if len(pkts) != len(exp) {
+		t.Fatalf("commands: %q", pkts)
+	}
+	for ii, e := range exp {
+		if pkts[ii] != e {
+			t.Fatalf("command %d: %q exp: %q", ii, pkts[ii], e)
+		}
+	}
+}
+
+func TestPipelineReadError(t *testing.T) {
+	// No responses and nobody reads commands, so the writer blocks
+	my := testConn(0, nil)
+	my.wr = bufio.NewWriter(my.net_conn)
+	p := my.Pipeline()
+	if err := p.Query("SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.Run()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != io.ErrUnexpectedEOF {
+			t.Fatalf("Run returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run blocked by writer after read error")
+	}
+	if my.IsConnected() {
+		t.Fatal("connection not closed after read error")
+	}
+}
@@ -15,6 +15,9 @@ type Stmt struct {
 	rebind bool
 	binded bool
 
+	param_fields []*mysql.Field // Parameter definitions reported by server
+	coerce       bool           // Check/convert bound values (see SetCoercion)
+
 	fields []*mysql.Field
 	fc_map map[string]int // Maps field name to column number
 
@@ -40,6 +43,21 @@ func (stmt *Stmt) NumParam() int {
 	return stmt.param_count
 }
 
+// Returns definitions of statement parameters reported by the server. Many
+// servers don't know the parameter types and report them as VARCHAR.
+func (stmt *Stmt) ParamFields() []*mysql.Field {
+	return stmt.param_fields
+}
+
+// Enables/disables coercion of values passed to subsequent Bind calls. If
+// enabled, values are checked against types reported by ParamFields and
+// converted to them (eg. int to TINYINT with range check, string to INT). Bind
+// panics with *mysql.BindError if a value can't be converted. For values
+// bound by pointer only their types are checked.
+func (stmt *Stmt) SetCoercion(on bool) {
+	stmt.coerce = on
+}
+
 func (stmt *Stmt) WarnCount() int {
 	return stmt.warning_count
 }
@@ -122,17 +140,24 @@ loop:
 			unreaded_params):
 			// Field packet
 			if unreaded_params {
-				// Read and ignore parameter field. Sentence from MySQL source:
-				/* skip parameters data: we don't support it yet */
-				my.getFieldPacket(pr)
+				// Parameter field
+				stmt.param_fields[stmt.param_count] = my.getFieldPacket(pr)
 				// Increment field count
 				stmt.param_count++
+				if stmt.param_count == len(stmt.params) && my.deprecateEof() {
+					// There is no EOF packet after parameters
+					return stmt
+				}
 			} else {
 				field := my.getFieldPacket(pr)
 				stmt.fields[stmt.field_count] = field
 				stmt.fc_map[field.Name] = stmt.field_count
 				// Increment field count
 				stmt.field_count++
+				if stmt.field_count == len(stmt.fields) && my.deprecateEof() {
+					// There is no EOF packet after fields
+					return stmt
+				}
 			}
 			// Read next packet
 			goto loop
@@ -152,6 +177,7 @@ func (my *Conn) getPrepareOkPacket(pr *pktReader) (stmt *Stmt) {
 	stmt.id = readU32(pr)
 	stmt.fields = make([]*mysql.Field, int(readU16(pr))) // FieldCount
 	stmt.params = make([]*paramValue, int(readU16(pr)))  // ParamCount
+	stmt.param_fields = make([]*mysql.Field, len(stmt.params))
 	read(pr, 1)
 	stmt.warning_count = int(readU16(pr))
 	pr.checkEof()
@@ -167,3 +193,75 @@ func (my *Conn) getPrepareOkPacket(pr *pktReader) (stmt *Stmt) {
 	}
 	return
 }
+
+// Like getResponse but returns server errors that require reprepare of stmt.
+func (stmt *Stmt) getExecResponse() (res *Result, cause *mysql.Error) {
+	defer func() {
+		if pv := recover(); pv != nil {
+			e, ok := pv.(*mysql.Error)
+			if !ok || e.Code != mysql.ER_NEED_REPREPARE &&
+				e.Code != mysql.ER_UNKNOWN_STMT_HANDLER {
+				panic(pv)
+			}
+			cause = e
+		}
+	}()
+	return stmt.my.getResponse(), nil
+}
+
+// Prepares stmt again using its saved sql. Binded parameters are preserved.
+// If it fails stmt is invalidated (as by Delete), because its server side
+// statement no longer exists.
+func (stmt *Stmt) reprepare(cause *mysql.Error) {
+	my := stmt.my
+	if cause.Code == mysql.ER_NEED_REPREPARE {
+		// Statement still exists on the server
+		my.sendCmd(_COM_STMT_CLOSE, stmt.id)
+	}
+	new_stmt, err := my.prepare(stmt.sql)
+	if err == nil && new_stmt.param_count != stmt.param_count {
+		my.sendCmd(_COM_STMT_CLOSE, new_stmt.id)
+		err = mysql.ErrBindCount
+	}
+	if err != nil {
+		delete(my.stmt_map, stmt.id)
+		my.stmt_cache.remove(stmt)
+		*stmt = Stmt{}
+		panic(err)
+	}
+	delete(my.stmt_map, stmt.id)
+	stmt.id = new_stmt.id
+	stmt.param_fields = new_stmt.param_fields
+	stmt.fields = new_stmt.fields
+	stmt.fc_map = new_stmt.fc_map
+	stmt.field_count = new_stmt.field_count
+	stmt.rebind = true
+	my.stmt_map[stmt.id] = stmt
+
+	if my.reprepare_handler != nil {
+		my.reprepare_handler(stmt, cause)
+	}
+}
+
+// Executes stmt. Reprepares it and executes again if the server reports that
+// it is needed (eg. table definition was changed).
+func (stmt *Stmt) execute() *Result {
+	stmt.sendCmdExec()
+	res, cause := stmt.getExecResponse()
+	if cause != nil {
+		if stmt.my.Debug {
+			log.Printf("Reprepare statement 0x%x: %s", stmt.id, cause)
+		}
+		stmt.reprepare(cause)
+		stmt.sendCmdExec()
+		res = stmt.my.getResponse()
+	}
+	return res
+}
+
+// Sets function that is called after statement was automatically reprepared
+// by Run. cause is the server error that caused reprepare. Use nil to remove
+// the handler.
+func (my *Conn) SetReprepareHandler(handler func(stmt *Stmt, cause error)) {
+	my.reprepare_handler = handler
+}
@@ -0,0 +1,95 @@
+package native
+
+import (
+	"bytes"
+	"github.com/ziutek/mymysql/mysql"
+	"testing"
+)
+
+var needReprepare = []byte(
+	"\xff\x4f\x06#HY000Prepared statement needs to be re-prepared",
+)
+
+func TestReprepare(t *testing.T) {
+	unknownStmt := []byte("\xff\xdb\x04#HY000Unknown prepared statement handler")
+	for _, cause := range [][]byte{needReprepare, unknownStmt} {
+		var out bytes.Buffer
+		my := testConn(
+			0, &out,
+			[]byte{0, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0},
+			fieldPkt("a", MYSQL_TYPE_LONG, 63), eofPkt, nil,
+			cause, nil,
+			[]byte{0, 2, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0},
+			fieldPkt("a", MYSQL_TYPE_LONG, 63),
+			fieldPkt("b", MYSQL_TYPE_LONG, 63), eofPkt, nil,
+			okPkt,
+		)
+		var (
+			reprepared *Stmt
+			code       uint16
+		)
+		my.SetReprepareHandler(func(stmt *Stmt, err error) {
+			reprepared = stmt
+			code = err.(*mysql.Error).Code
+		})
+		st, err := my.Prepare("SELECT * FROM t")
+		if err != nil {
+			t.Fatal(err)
+		}
+		stmt := st.(*Stmt)
+		if _, err = stmt.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if reprepared != stmt || code != mysql.ER_NEED_REPREPARE &&
+			code != mysql.ER_UNKNOWN_STMT_HANDLER {
+			t.Fatalf("bad handler call: %v %d", reprepared, code)
+		}
+		if stmt.id != 2 || stmt.NumField() != 2 || stmt.Map("b") != 1 ||
+			len(my.stmt_map) != 1 || my.stmt_map[2] != stmt {
+			t.Fatalf("statement not reprepared: %+v", stmt)
+		}
+		exp := []string{"\x16SELECT * FROM t", "\x17\x01"}
+		if code == mysql.ER_NEED_REPREPARE {
+			exp = append(exp, "\x19\x01")
+		}
+		exp = append(exp, "\x16SELECT * FROM t", "\x17\x02")
+		pkts := cmdPkts(&out)
+		if len(pkts) != len(exp) {
+			t.Fatalf("commands: %q", pkts)
+		}
+		for ii, e := range exp {
+			if pkts[ii][:len(e)] != e {
+				t.Fatalf("command %d: %q exp: %q", ii, pkts[ii], e)
+			}
+		}
+	}
+}
+
+func TestReprepareParamCount(t *testing.T) {
+	var out bytes.Buffer
+	my := testConn(
+		0, &out,
+		prepOkPkt(1), nil,
+		needReprepare, nil,
+		[]byte{0, 2, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0},
+		fieldPkt("?", MYSQL_TYPE_LONG, 63), eofPkt,
+	)
+	st, err := my.Prepare("DO f()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt := st.(*Stmt)
+	if _, err = stmt.Run(); err != mysql.ErrBindCount {
+		t.Fatalf("Run returned %v", err)
+	}
+	if stmt.my != nil || len(my.stmt_map) != 0 {
+		t.Fatalf("statement not invalidated: %+v", stmt)
+	}
+	checkCmds(t, &out,
+		"\x16DO f()",
+		"\x17\x01\x00\x00\x00\x00\x01\x00\x00\x00\x00",
+		"\x19\x01\x00\x00\x00",
+		"\x16DO f()",
+		"\x19\x02\x00\x00\x00",
+	)
+}
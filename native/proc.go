@@ -0,0 +1,244 @@
+package native
+
+import (
+	"fmt"
+	"github.com/ziutek/mymysql/mysql"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Assigns n-th value of row to the variable pointed by ptr (OUT or INOUT
+// parameter). NULL is assigned as zero value (nil for pointer variables).
+func scanOutParam(row mysql.Row, n int, ptr reflect.Value) (err error) {
+	v := ptr.Elem()
+	if row[n] == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	switch v.Type() {
+	case timeType:
+		var t time.Time
+		if t, err = row.TimeErr(n, time.Local); err == nil {
+			v.Set(reflect.ValueOf(t))
+		}
+		return
+	case dateType:
+		var d mysql.Date
+		if d, err = row.DateErr(n); err == nil {
+			v.Set(reflect.ValueOf(d))
+		}
+		return
+	case durationType:
+		var d time.Duration
+		if d, err = row.DurationErr(n); err == nil {
+			v.SetInt(int64(d))
+		}
+		return
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(row[n]))
+	case reflect.String:
+		v.SetString(row.Str(n))
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return mysql.ErrBindUnkType
+		}
+		v.SetBytes(row.Bin(n))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = row.Int64Err(n); err != nil {
+			return
+		}
+		if v.OverflowInt(i) {
+			return strconv.ErrRange
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		var u uint64
+		if u, err = row.Uint64Err(n); err != nil {
+			return
+		}
+		if v.OverflowUint(u) {
+			return strconv.ErrRange
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = row.FloatErr(n); err == nil {
+			v.SetFloat(f)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = row.BoolErr(n); err == nil {
+			v.SetBool(b)
+		}
+	default:
+		return mysql.ErrBindUnkType
+	}
+	return
+}
+
+// Assigns values of OUT parameters from row to variables pointed by outs.
+func scanOutParams(row mysql.Row, outs []reflect.Value) error {
+	if len(row) != len(outs) {
+		return mysql.ErrBadResult
+	}
+	for ii, ptr := range outs {
+		if err := scanOutParam(row, ii, ptr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reads rows of all results starting from res. Rows of result sets that
+// contain OUT parameters (marked by SERVER_PS_OUT_PARAMS) are assigned to
+// outs. Returns rows of other result sets and the final status result. If
+// OUT parameters can't be assigned, all results are read anyway and the
+// first assignment error is returned.
+func (my *Conn) procResults(res *Result, outs []reflect.Value) (rows [][]mysql.Row, last *Result, err error) {
+	binary := res.binary
+	for {
+		if !res.StatusOnly() {
+			rs, e := mysql.GetRows(res)
+			if e != nil {
+				panic(e)
+			}
+			if res.status&_SERVER_PS_OUT_PARAMS != 0 {
+				if len(rs) == 1 {
+					if e = scanOutParams(rs[0], outs); err == nil {
+						err = e
+					}
+				}
+			} else {
+				rows = append(rows, rs)
+			}
+		}
+		if !res.MoreResults() {
+			return rows, res, err
+		}
+		res = my.getResponse()
+		res.binary = binary
+	}
+}
+
+// Returns name of user variable used to pass n-th parameter of a procedure
+// if the server can't return OUT parameters of a prepared CALL.
+func procVar(n int) string {
+	return "@_mymysql_p" + strconv.Itoa(n)
+}
+
+// Calls procedure using user variables: SET @vars = ?..., CALL name(@vars),
+// SELECT @outs.
+func (my *Conn) callProcVars(name string, params []interface{}, outs []reflect.Value, out_nums []int) ([][]mysql.Row, *Result, error) {
+	vars := make([]string, len(params))
+	for ii := range params {
+		vars[ii] = procVar(ii)
+	}
+	if len(params) != 0 {
+		set, err := my.prepareCached("SET " + strings.Join(vars, " = ?, ") + " = ?")
+		if err != nil {
+			panic(err)
+		}
+		if _, _, err = set.Exec(params...); err != nil {
+			panic(err)
+		}
+	}
+	my.sendCmd(_COM_QUERY, my.encodeQuery(
+		"CALL "+name+"("+strings.Join(vars, ", ")+")",
+	))
+	rows, last, err := my.procResults(my.getResponse(), nil)
+	if err != nil || len(outs) == 0 {
+		return rows, last, err
+	}
+	sel := make([]string, len(out_nums))
+	for ii, n := range out_nums {
+		sel[ii] = vars[n]
+	}
+	my.sendCmd(_COM_QUERY, my.encodeQuery("SELECT "+strings.Join(sel, ", ")))
+	res := my.getResponse()
+	res.typed_text = true
+	out_rows, err := mysql.GetRows(res)
+	if err != nil {
+		panic(err)
+	}
+	if len(out_rows) != 1 {
+		return rows, last, mysql.ErrBadResult
+	}
+	return rows, last, scanOutParams(out_rows[0], outs)
+}
+
+// Calls stored procedure name (inserted into the CALL statement as is) with
+// params. Pointers in params are OUT or INOUT parameters: values they point
+// to are passed to the procedure and replaced by values of the parameters
+// after the call (NULL is stored as zero value or nil pointer if pointer to
+// pointer was passed). Other values are IN parameters.
+//
+// If the server can return OUT parameters of prepared statements
+// (CLIENT_PS_MULTI_RESULTS), the procedure is called using the cached
+// prepared statement (see PrepareCached). Otherwise parameters are passed
+// using user variables named @_mymysql_pN.
+//
+// Returns rows of all result sets produced by the procedure and the final
+// status result of the call. If an OUT parameter can't be assigned (eg. value
+// overflows the variable) the error is returned after all results are read,
+// so the connection remains usable.
+func (my *Conn) CallProc(name string, params ...interface{}) (rows [][]mysql.Row, res mysql.Result, err error) {
+	defer catchError(&err)
+
+	if my.net_conn == nil {
+		return nil, nil, mysql.ErrNotConn
+	}
+	if my.unreaded_reply {
+		return nil, nil, mysql.ErrUnreadedReply
+	}
+
+	var (
+		outs     []reflect.Value
+		out_nums []int
+	)
+	in := make([]interface{}, len(params))
+	for ii, par := range params {
+		in[ii] = par
+		pval := reflect.ValueOf(par)
+		if pval.Kind() != reflect.Ptr {
+			continue
+		}
+		if pval.IsNil() {
+			panic(mysql.ErrBindUnkType)
+		}
+		if pval.Elem().Kind() == reflect.Interface {
+			// Pass value stored in the interface variable
+			in[ii] = pval.Elem().Interface()
+		}
+		outs = append(outs, pval)
+		out_nums = append(out_nums, ii)
+	}
+
+	var last *Result
+	if my.caps&_CLIENT_PS_MULTI_RESULTS == 0 {
+		rows, last, err = my.callProcVars(name, in, outs, out_nums)
+		return rows, last, err
+	}
+	marks := strings.TrimSuffix(strings.Repeat("?, ", len(params)), ", ")
+	stmt, err := my.prepareCached(fmt.Sprintf("CALL %s(%s)", name, marks))
+	if err != nil {
+		return
+	}
+	r, err := stmt.Run(in...)
+	if err != nil {
+		return
+	}
+	rows, last, err = my.procResults(r.(*Result), outs)
+	return rows, last, err
+}
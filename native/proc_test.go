@@ -0,0 +1,112 @@
+package native
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+func eofStatusPkt(status uint16) []byte {
+	return []byte{0xfe, 0, 0, byte(status), byte(status >> 8)}
+}
+
+func TestCallProc(t *testing.T) {
+	more := uint16(_SERVER_STATUS_AUTOCOMMIT | _SERVER_MORE_RESULTS_EXISTS)
+	outs := more | _SERVER_PS_OUT_PARAMS
+	var out bytes.Buffer
+	my := testConn(
+		_CLIENT_PROTOCOL_41|_CLIENT_PS_MULTI_RESULTS, &out,
+		[]byte{0, 1, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0},
+		fieldPkt("?", MYSQL_TYPE_LONGLONG, 63),
+		fieldPkt("?", MYSQL_TYPE_LONGLONG, 63),
+		fieldPkt("?", MYSQL_TYPE_VAR_STRING, 33),
+		eofPkt, nil,
+		// Result set produced by the procedure
+		[]byte{1}, fieldPkt("a", MYSQL_TYPE_LONGLONG, 63), eofStatusPkt(more),
+		[]byte{0, 0, 9, 0, 0, 0, 0, 0, 0, 0}, eofStatusPkt(more),
+		// OUT parameters
+		[]byte{2},
+		fieldPkt("x", MYSQL_TYPE_LONGLONG, 63),
+		fieldPkt("s", MYSQL_TYPE_VAR_STRING, 33),
+		eofStatusPkt(outs),
+		[]byte{0, 0, 7, 0, 0, 0, 0, 0, 0, 0, 2, 'o', 'k'}, eofStatusPkt(outs),
+		[]byte{0, 0, 0, 2, 0, 0, 0},
+	)
+	x := 5
+	var s *string
+	rows, res, err := my.CallProc("p", int64(1), &x, &s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x != 7 || s == nil || *s != "ok" {
+		t.Fatalf("bad OUT parameters: x=%d s=%v", x, s)
+	}
+	if len(rows) != 1 || len(rows[0]) != 1 || rows[0][0].Int(0) != 9 {
+		t.Fatalf("bad rows: %v", rows)
+	}
+	if !res.StatusOnly() || res.MoreResults() || my.unreaded_reply {
+		t.Fatalf("bad final result: %+v", res)
+	}
+	if pkts := cmdPkts(&out); len(pkts) != 2 || pkts[0] != "\x16CALL p(?, ?, ?)" {
+		t.Fatalf("commands: %q", pkts)
+	}
+}
+
+func TestCallProcVars(t *testing.T) {
+	var out bytes.Buffer
+	my := testConn(
+		_CLIENT_PROTOCOL_41, &out,
+		[]byte{0, 1, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0},
+		fieldPkt("?", MYSQL_TYPE_NULL, 63),
+		fieldPkt("?", MYSQL_TYPE_NULL, 63),
+		eofPkt, nil,
+		okPkt, nil,
+		okPkt, nil,
+		[]byte{1}, fieldPkt("@_mymysql_p1", MYSQL_TYPE_LONGLONG, 63), eofPkt,
+		[]byte{1, '7'}, eofPkt,
+	)
+	var x interface{} = int64(5)
+	if _, _, err := my.CallProc("db.p", "a", &x); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := x.(int64); !ok || v != 7 {
+		t.Fatalf("bad OUT parameter: %#v", x)
+	}
+	pkts := cmdPkts(&out)
+	if len(pkts) != 4 ||
+		pkts[0] != "\x16SET @_mymysql_p0 = ?, @_mymysql_p1 = ?" ||
+		pkts[2] != "\x03CALL db.p(@_mymysql_p0, @_mymysql_p1)" ||
+		pkts[3] != "\x03SELECT @_mymysql_p1" {
+		t.Fatalf("commands: %q", pkts)
+	}
+}
+
+func TestCallProcOutOverflow(t *testing.T) {
+	more := uint16(_SERVER_STATUS_AUTOCOMMIT | _SERVER_MORE_RESULTS_EXISTS)
+	outs := more | _SERVER_PS_OUT_PARAMS
+	var out bytes.Buffer
+	my := testConn(
+		_CLIENT_PROTOCOL_41|_CLIENT_PS_MULTI_RESULTS, &out,
+		[]byte{0, 1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0},
+		fieldPkt("?", MYSQL_TYPE_LONGLONG, 63),
+		eofPkt, nil,
+		// OUT parameter that doesn't fit in int8
+		[]byte{1},
+		fieldPkt("x", MYSQL_TYPE_LONGLONG, 63),
+		eofStatusPkt(outs),
+		[]byte{0, 0, 0x2c, 1, 0, 0, 0, 0, 0, 0}, eofStatusPkt(outs),
+		[]byte{0, 0, 0, 2, 0, 0, 0}, nil,
+		okPkt,
+	)
+	var x int8
+	_, res, err := my.CallProc("p", &x)
+	if err != strconv.ErrRange {
+		t.Fatalf("CallProc returned %v", err)
+	}
+	if res == nil || res.MoreResults() || my.unreaded_reply {
+		t.Fatalf("results not read: %+v", res)
+	}
+	if _, _, err = my.Query("DO 1"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,272 @@
+package native
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/ziutek/mymysql/mysql"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// Returns connection that reads server responses (pkts are packet payloads,
+// nil separates responses to subsequent commands) from memory and writes
+// commands to wr.
+func testConn(caps uint32, wr io.Writer, pkts ...[]byte) *Conn {
+	var buf bytes.Buffer
+	seq := byte(1)
+	for _, pkt := range pkts {
+		if pkt == nil {
+			seq = 1
+			continue
+		}
+		writeU24(&buf, uint32(len(pkt)))
+		buf.WriteByte(seq)
+		buf.Write(pkt)
+		seq++
+	}
+	my := New("tcp", "", "", "user", "").(*Conn)
+	my.caps = caps
+	my.rd = bufio.NewReader(&buf)
+	if wr == nil {
+		wr = new(bytes.Buffer)
+	}
+	my.wr = bufio.NewWriter(wr)
+	my.seq = 1
+	my.net_conn, _ = net.Pipe()
+	return my
+}
+
+// Returns payload of column definition packet
+func fieldPkt(name string, typ byte, charset uint16) []byte {
+	var buf bytes.Buffer
+	for _, s := range []string{"def", "db", "t", "t", name, name} {
+		writeStr(&buf, s)
+	}
+	buf.WriteByte(0x0c)
+	writeU16(&buf, charset)
+	writeU32(&buf, 10)
+	buf.WriteByte(typ)
+	writeU16(&buf, 0)
+	buf.WriteByte(0)
+	buf.Write([]byte{0, 0})
+	return buf.Bytes()
+}
+
+func checkTextResult(t *testing.T, my *Conn) {
+	res := my.getResponse()
+	if res.StatusOnly() || len(res.Fields()) != 1 || res.Map("a") != 0 {
+		t.Fatalf("bad result set header: %+v", res)
+	}
+	row := res.MakeRow()
+	if err := res.ScanRow(row); err != nil {
+		t.Fatal(err)
+	}
+	if row.Str(0) != "x" {
+		t.Fatalf("bad row: %v", row)
+	}
+	if err := res.ScanRow(row); err != io.EOF {
+		t.Fatalf("ScanRow returned %v instead of io.EOF", err)
+	}
+	if res.WarnCount() != 1 || res.status != _SERVER_STATUS_AUTOCOMMIT ||
+		my.unreaded_reply {
+		t.Fatalf("bad status after rows: %+v", res)
+	}
+}
+
+func TestResultEof(t *testing.T) {
+	checkTextResult(t, testConn(
+		_CLIENT_PROTOCOL_41, nil,
+		[]byte{1},
+		fieldPkt("a", MYSQL_TYPE_VAR_STRING, 33),
+		[]byte{0xfe, 0, 0, 0, 0},
+		[]byte("\x01x"),
+		[]byte{0xfe, 1, 0, byte(_SERVER_STATUS_AUTOCOMMIT), 0},
+	))
+}
+
+func TestResultDeprecateEof(t *testing.T) {
+	checkTextResult(t, testConn(
+		_CLIENT_PROTOCOL_41|_CLIENT_DEPRECATE_EOF, nil,
+		[]byte{1},
+		fieldPkt("a", MYSQL_TYPE_VAR_STRING, 33),
+		[]byte("\x01x"),
+		[]byte{0xfe, 0, 0, byte(_SERVER_STATUS_AUTOCOMMIT), 0, 1, 0},
+	))
+}
+
+func TestPrepareDeprecateEof(t *testing.T) {
+	my := testConn(
+		_CLIENT_PROTOCOL_41|_CLIENT_DEPRECATE_EOF, nil,
+		[]byte{0, 7, 0, 0, 0, 1, 0, 2, 0, 0, 0, 0},
+		fieldPkt("?", MYSQL_TYPE_VAR_STRING, 63),
+		fieldPkt("?", MYSQL_TYPE_VAR_STRING, 63),
+		fieldPkt("a", MYSQL_TYPE_LONG, 63),
+	)
+	stmt, err := my.prepare("SELECT a FROM t WHERE b=? AND c=?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt.id != 7 || stmt.NumParam() != 2 || stmt.NumField() != 1 ||
+		stmt.Map("a") != 0 {
+		t.Fatalf("bad statement: %+v", stmt)
+	}
+	if _, err := my.rd.Peek(1); err != io.EOF {
+		t.Fatal("unread data after prepare")
+	}
+}
+
+func TestInitCaps(t *testing.T) {
+	var pkt bytes.Buffer
+	pkt.WriteByte(10)
+	writeNTS(&pkt, "8.0.36")
+	writeU32(&pkt, 5)
+	pkt.WriteString("12345678\x00")
+	writeU16(&pkt, uint16(_CLIENT_PROTOCOL_41|_CLIENT_SECURE_CONN))
+	pkt.WriteByte(33)
+	writeU16(&pkt, _SERVER_STATUS_AUTOCOMMIT)
+	writeU16(&pkt, uint16((_CLIENT_MULTI_RESULTS|_CLIENT_DEPRECATE_EOF)>>16))
+	pkt.WriteByte(21)
+	pkt.Write(make([]byte, 10))
+	pkt.WriteString("abcdefghijkl\x00mysql_native_password\x00")
+
+	var buf bytes.Buffer
+	writeU24(&buf, uint32(pkt.Len()))
+	buf.WriteByte(0) // Handshake is the first packet
+	buf.Write(pkt.Bytes())
+	my := testConn(0, nil)
+	my.rd = bufio.NewReader(&buf)
+	my.init()
+	exp := uint32(_CLIENT_PROTOCOL_41 | _CLIENT_SECURE_CONN |
+		_CLIENT_MULTI_RESULTS | _CLIENT_DEPRECATE_EOF)
+	if my.info.caps != exp {
+		t.Fatalf("caps: 0x%x exp: 0x%x", my.info.caps, exp)
+	}
+	if string(my.info.scramble) != "12345678abcdefghijkl" {
+		t.Fatalf("scramble: %q", my.info.scramble)
+	}
+	my.auth()
+	if my.caps != exp {
+		t.Fatalf("negotiated caps: 0x%x exp: 0x%x", my.caps, exp)
+	}
+}
+
+// Returns session state entry of type typ
+func sessionEntry(typ byte, data ...string) []byte {
+	var d bytes.Buffer
+	if typ == _SESSION_TRACK_GTIDS {
+		d.WriteByte(0)
+	}
+	for _, s := range data {
+		writeStr(&d, s)
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(typ)
+	writeBin(&buf, d.Bytes())
+	return buf.Bytes()
+}
+
+func TestSessionTrack(t *testing.T) {
+	var state bytes.Buffer
+	state.Write(sessionEntry(_SESSION_TRACK_SCHEMA, "db2"))
+	state.Write(sessionEntry(_SESSION_TRACK_SYSTEM_VARIABLES, "autocommit", "OFF"))
+	state.Write(sessionEntry(_SESSION_TRACK_GTIDS, "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5"))
+	state.Write(sessionEntry(_SESSION_TRACK_TRANSACTION_STATE, "T_______"))
+	state.Write(sessionEntry(9, "unknown"))
+	var ok bytes.Buffer
+	ok.Write([]byte{0, 0, 0})
+	writeU16(&ok, _SERVER_STATUS_IN_TRANS|_SERVER_SESSION_STATE_CHANGED)
+	writeU16(&ok, 0)
+	writeStr(&ok, "")
+	writeBin(&ok, state.Bytes())
+
+	my := testConn(
+		_CLIENT_PROTOCOL_41|_CLIENT_SESSION_TRACK, nil,
+		ok.Bytes(),
+		[]byte{0, 1, 0, 2, 0, 0, 0, 3, 'a', 'b', 'c'},
+		[]byte{0, 0, 0, 2, 0, 0, 0},
+	)
+	res := my.getResponse()
+	ss := res.SessionState()
+	exp := &mysql.SessionState{
+		Changes: mysql.SESSION_TRACK_SCHEMA |
+			mysql.SESSION_TRACK_SYSTEM_VARIABLES |
+			mysql.SESSION_TRACK_GTIDS | mysql.SESSION_TRACK_TRANSACTION_STATE,
+		Schema:    "db2",
+		Variables: map[string]string{"autocommit": "OFF"},
+		GTIDs:     "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5",
+		TxState:   "T_______",
+	}
+	if !reflect.DeepEqual(ss, exp) {
+		t.Fatalf("session state: %+v exp: %+v", ss, exp)
+	}
+	if !reflect.DeepEqual(my.SessionState(), exp) {
+		t.Fatalf("conn session state: %+v", my.SessionState())
+	}
+
+	// Info without session state
+	res = my.getResponse()
+	if res.Message() != "abc" || res.AffectedRows() != 1 ||
+		res.SessionState() != nil {
+		t.Fatalf("bad OK result: %+v", res)
+	}
+	// OK packet without info
+	res = my.getResponse()
+	if res.Message() != "" || res.SessionState() != nil {
+		t.Fatalf("bad OK result: %+v", res)
+	}
+	if !my.SessionState().Changed(mysql.SESSION_TRACK_SCHEMA) {
+		t.Fatal("conn session state lost")
+	}
+	my.ClearSessionState()
+	if my.SessionState().Changed(mysql.SESSION_TRACK_SCHEMA) {
+		t.Fatal("conn session state not cleared")
+	}
+}
+
+func TestConnectAttrs(t *testing.T) {
+	var out bytes.Buffer
+	my := testConn(0, &out)
+	if my.Attrs()["_client_name"] != "mymysql" || my.Attrs()["_pid"] == "" {
+		t.Fatalf("bad default attributes: %v", my.Attrs())
+	}
+	for name := range my.Attrs() {
+		delete(my.Attrs(), name)
+	}
+	my.SetAttr("b", "2")
+	my.SetAttr("a", "1")
+	my.info.scramble = make([]byte, 20)
+
+	for _, caps := range []uint32{0, _CLIENT_CONNECT_ATTRS} {
+		out.Reset()
+		my.info.caps = _CLIENT_PROTOCOL_41 | caps
+		my.auth()
+		my.wr.Flush()
+		pkt := out.Bytes()
+		hasAttrs := bytes.HasSuffix(pkt, []byte("\x08\x01a\x011\x01b\x012"))
+		if caps == 0 && (hasAttrs || my.caps&_CLIENT_CONNECT_ATTRS != 0) {
+			t.Fatalf("attributes sent to server that doesn't support them: %q", pkt)
+		}
+		if caps != 0 && (!hasAttrs || my.caps&_CLIENT_CONNECT_ATTRS == 0) {
+			t.Fatalf("attributes not sent: %q", pkt)
+		}
+		if int(readU24(bytes.NewReader(pkt))) != len(pkt)-4 {
+			t.Fatalf("bad packet length: %q", pkt)
+		}
+	}
+	if c := my.Clone().(*Conn); !reflect.DeepEqual(c.Attrs(), my.Attrs()) {
+		t.Fatalf("Clone attributes: %v", c.Attrs())
+	}
+
+	// No attributes: empty attribute block is sent
+	delete(my.Attrs(), "a")
+	delete(my.Attrs(), "b")
+	out.Reset()
+	my.auth()
+	my.wr.Flush()
+	pkt := out.Bytes()
+	if int(readU24(bytes.NewReader(pkt))) != len(pkt)-4 || pkt[len(pkt)-1] != 0 {
+		t.Fatalf("bad packet without attributes: %q", pkt)
+	}
+}
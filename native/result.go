@@ -5,12 +5,14 @@ import (
 	"log"
 	"math"
 	"strconv"
+	"time"
 )
 
 type Result struct {
 	my          *Conn
 	status_only bool // true if result doesn't contain result set
 	binary      bool // Binary result expected
+	typed_text  bool // Convert text rows to types used by binary rows
 
 	field_count int
 	fields      []*mysql.Field // Fields table
@@ -29,6 +31,9 @@ type Result struct {
 	// MySQL server status immediately after the query execution
 	status uint16
 
+	// Session state changes reported in OK packet
+	session *mysql.SessionState
+
 	// Seted by GetRow if it returns nil row
 	eor_returned bool
 }
@@ -67,6 +72,15 @@ func (res *Result) WarnCount() int {
 	return res.warning_count
 }
 
+// Enables/disables conversion of values in text protocol rows of this
+// result to the same types that are used for rows of prepared statement
+// results. Affects rows read after the call. Returns old value.
+func (res *Result) SetTypedText(on bool) bool {
+	old := res.typed_text
+	res.typed_text = on
+	return old
+}
+
 func (res *Result) MakeRow() mysql.Row {
 	return make(mysql.Row, res.field_count)
 }
@@ -102,8 +116,9 @@ loop:
 		}
 	} else {
 		switch {
-		case pkt0 == 254:
-			// EOF packet
+		case pkt0 == 254 && pr.last:
+			// EOF packet (OK packet if EOF is deprecated). Row packet that
+			// begins with 254 is always longer than 0xffffff bytes.
 			res.warning_count, res.status = my.getEofPacket(pr)
 			my.status = res.status
 			return res
@@ -115,10 +130,14 @@ loop:
 			res.fc_map[field.Name] = res.field_count
 			// Increment field count
 			res.field_count++
+			if res.field_count == len(res.fields) && my.deprecateEof() {
+				// There is no EOF packet after fields
+				return res
+			}
 			// Read next packet
 			goto loop
 
-		case pkt0 < 254 && res.field_count == len(res.fields):
+		case res.field_count == len(res.fields):
 			// Row Data Packet
 			if len(row) != res.field_count {
 				panic(mysql.ErrRowLength)
@@ -147,7 +166,7 @@ func (my *Conn) getOkPacket(pr *pktReader) (res *Result) {
 	res.status = readU16(pr)
 	my.status = res.status
 	res.warning_count = int(readU16(pr))
-	res.message = pr.readAll()
+	res.message, res.session = my.getOkInfo(pr, res.status)
 	pr.checkEof()
 
 	if my.Debug {
@@ -178,12 +197,26 @@ func (my *Conn) getErrorPacket(pr *pktReader) {
 	panic(&err)
 }
 
+// True if server sends OK packets instead of EOF packets
+func (my *Conn) deprecateEof() bool {
+	return my.caps&_CLIENT_DEPRECATE_EOF != 0
+}
+
 func (my *Conn) getEofPacket(pr *pktReader) (warn_count int, status uint16) {
 	if my.Debug {
 		log.Printf("[%2d ->] EOF packet:", my.seq-1)
 	}
-	warn_count = int(readU16(pr))
-	status = readU16(pr)
+	if my.deprecateEof() {
+		// OK packet with EOF header
+		readLCB(pr) // Affected rows
+		readLCB(pr) // Insert id
+		status = readU16(pr)
+		warn_count = int(readU16(pr))
+		my.getOkInfo(pr, status)
+	} else {
+		warn_count = int(readU16(pr))
+		status = readU16(pr)
+	}
 	pr.checkEof()
 
 	if my.Debug {
@@ -202,9 +235,10 @@ func (my *Conn) getResSetHeadPacket(pr *pktReader) (res *Result) {
 	pr.checkEof()
 
 	res = &Result{
-		my:     my,
-		fields: make([]*mysql.Field, field_count),
-		fc_map: make(map[string]int),
+		my:         my,
+		fields:     make([]*mysql.Field, field_count),
+		fc_map:     make(map[string]int),
+		typed_text: my.typed_text,
 	}
 
 	if my.Debug {
@@ -219,6 +253,17 @@ func (my *Conn) getFieldPacket(pr *pktReader) (field *mysql.Field) {
 	}
 	pr.unreadByte()
 
+	field = readField(pr)
+	pr.checkEof()
+
+	if my.Debug {
+		log.Printf(tab8s+"Name=\"%s\" Type=0x%x", field.Name, field.Type)
+	}
+	return
+}
+
+// Reads column definition
+func readField(pr *pktReader) (field *mysql.Field) {
 	field = new(mysql.Field)
 	field.Catalog = readStr(pr)
 	field.Db = readStr(pr)
@@ -226,18 +271,13 @@ func (my *Conn) getFieldPacket(pr *pktReader) (field *mysql.Field) {
 	field.OrgTable = readStr(pr)
 	field.Name = readStr(pr)
 	field.OrgName = readStr(pr)
-	read(pr, 1+2)
-	//field.Charset= readU16(pr)
+	read(pr, 1)
+	field.Charset = readU16(pr)
 	field.DispLen = readU32(pr)
 	field.Type = readByte(pr)
 	field.Flags = readU16(pr)
 	field.Scale = readByte(pr)
 	read(pr, 2)
-	pr.checkEof()
-
-	if my.Debug {
-		log.Printf(tab8s+"Name=\"%s\" Type=0x%x", field.Name, field.Type)
-	}
 	return
 }
 
@@ -247,17 +287,91 @@ func (my *Conn) getTextRowPacket(pr *pktReader, res *Result, row mysql.Row) {
 	}
 	pr.unreadByte()
 
-	for ii := 0; ii < res.field_count; ii++ {
+	for ii, field := range res.fields {
 		bin, null := readNullBin(pr)
-		if null {
+		switch {
+		case null:
 			row[ii] = nil
-		} else {
-			row[ii] = bin
+		case res.typed_text:
+			row[ii] = textValue(field, my.decodeValue(field, bin))
+		default:
+			row[ii] = my.decodeValue(field, bin)
 		}
 	}
 	pr.checkEof()
 }
 
+// Converts text protocol value to the type that getBinRowPacket returns
+// for the field. Value that can't be converted (eg. zero-in-date datetime)
+// is returned as is, so the rest of the row can be read.
+func textValue(field *mysql.Field, bin []byte) interface{} {
+	str := string(bin)
+	unsigned := (field.Flags & _FLAG_UNSIGNED) != 0
+	var (
+		val interface{}
+		err error
+	)
+	switch field.Type {
+	case MYSQL_TYPE_TINY, MYSQL_TYPE_SHORT, MYSQL_TYPE_YEAR, MYSQL_TYPE_LONG,
+		MYSQL_TYPE_INT24, MYSQL_TYPE_LONGLONG:
+		bits := 64
+		switch field.Type {
+		case MYSQL_TYPE_TINY:
+			bits = 8
+		case MYSQL_TYPE_SHORT, MYSQL_TYPE_YEAR:
+			bits = 16
+		case MYSQL_TYPE_LONG, MYSQL_TYPE_INT24:
+			bits = 32
+		}
+		if unsigned {
+			var u uint64
+			u, err = strconv.ParseUint(str, 10, bits)
+			switch bits {
+			case 8:
+				val = uint8(u)
+			case 16:
+				val = uint16(u)
+			case 32:
+				val = uint32(u)
+			default:
+				val = u
+			}
+		} else {
+			var i int64
+			i, err = strconv.ParseInt(str, 10, bits)
+			switch bits {
+			case 8:
+				val = int8(i)
+			case 16:
+				val = int16(i)
+			case 32:
+				val = int32(i)
+			default:
+				val = i
+			}
+		}
+	case MYSQL_TYPE_FLOAT:
+		var f float64
+		f, err = strconv.ParseFloat(str, 32)
+		val = float32(f)
+	case MYSQL_TYPE_DOUBLE, MYSQL_TYPE_DECIMAL, MYSQL_TYPE_NEWDECIMAL:
+		val, err = strconv.ParseFloat(str, 64)
+	case MYSQL_TYPE_DATE, MYSQL_TYPE_NEWDATE:
+		val, err = mysql.ParseDate(str)
+	case MYSQL_TYPE_DATETIME, MYSQL_TYPE_TIMESTAMP:
+		val, err = mysql.ParseTime(str, time.Local)
+	case MYSQL_TYPE_TIME:
+		val, err = mysql.ParseDuration(str)
+	default:
+		// Strings, blobs, BIT, ENUM, SET, GEOMETRY, JSON
+		return bin
+	}
+	if err != nil {
+		return bin
+	}
+	return val
+}
+
 func (my *Conn) getBinRowPacket(pr *pktReader, res *Result, row mysql.Row) {
 	if my.Debug {
 		log.Printf("[%2d ->] Binary row data packet", my.seq-1)
@@ -316,8 +430,8 @@ func (my *Conn) getBinRowPacket(pr *pktReader, res *Result, row mysql.Row) {
 		case MYSQL_TYPE_STRING, MYSQL_TYPE_VAR_STRING, MYSQL_TYPE_VARCHAR,
 			MYSQL_TYPE_BIT, MYSQL_TYPE_BLOB, MYSQL_TYPE_TINY_BLOB,
 			MYSQL_TYPE_MEDIUM_BLOB, MYSQL_TYPE_LONG_BLOB, MYSQL_TYPE_SET,
-			MYSQL_TYPE_ENUM, MYSQL_TYPE_GEOMETRY:
-			row[ii] = readBin(pr)
+			MYSQL_TYPE_ENUM, MYSQL_TYPE_GEOMETRY, MYSQL_TYPE_JSON:
+			row[ii] = my.decodeValue(field, readBin(pr))
 		case MYSQL_TYPE_DATE, MYSQL_TYPE_NEWDATE:
 			row[ii] = readDate(pr)
 		case MYSQL_TYPE_DATETIME, MYSQL_TYPE_TIMESTAMP:
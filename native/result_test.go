@@ -0,0 +1,70 @@
+package native
+
+import (
+	"github.com/ziutek/mymysql/mysql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTextValue(t *testing.T) {
+	tests := []struct {
+		typ   byte
+		flags uint16
+		in    string
+		exp   interface{}
+	}{
+		{MYSQL_TYPE_TINY, 0, "-128", int8(-128)},
+		{MYSQL_TYPE_TINY, _FLAG_UNSIGNED, "255", uint8(255)},
+		{MYSQL_TYPE_YEAR, _FLAG_UNSIGNED, "2014", uint16(2014)},
+		{MYSQL_TYPE_INT24, 0, "-8388608", int32(-8388608)},
+		{MYSQL_TYPE_LONG, _FLAG_UNSIGNED, "4294967295", uint32(4294967295)},
+		{MYSQL_TYPE_LONGLONG, 0, "-1", int64(-1)},
+		{MYSQL_TYPE_LONGLONG, _FLAG_UNSIGNED, "18446744073709551615",
+			uint64(18446744073709551615)},
+		{MYSQL_TYPE_FLOAT, 0, "1.5", float32(1.5)},
+		{MYSQL_TYPE_NEWDECIMAL, 0, "-12.25", -12.25},
+		{MYSQL_TYPE_DATE, 0, "2014-03-04", mysql.Date{Year: 2014, Month: 3, Day: 4}},
+		{MYSQL_TYPE_DATE, 0, "0000-00-00", mysql.Date{}},
+		{MYSQL_TYPE_DATETIME, 0, "2014-03-04 05:06:07.5",
+			time.Date(2014, 3, 4, 5, 6, 7, 5e8, time.Local)},
+		{MYSQL_TYPE_TIMESTAMP, 0, "0000-00-00 00:00:00", mysql.ZeroTime},
+		{MYSQL_TYPE_DATE, 0, "2014-00-00", mysql.Date{Year: 2014}},
+		{MYSQL_TYPE_DATETIME, 0, "2014-00-00 00:00:00",
+			[]byte("2014-00-00 00:00:00")},
+		{MYSQL_TYPE_TIME, 0, "-838:59:59", -time.Duration(3020399e9)},
+		{MYSQL_TYPE_TINY, 0, "300", []byte("300")},
+		{MYSQL_TYPE_VAR_STRING, 0, "abc", []byte("abc")},
+		{MYSQL_TYPE_BLOB, _FLAG_UNSIGNED, "1", []byte("1")},
+	}
+	for _, test := range tests {
+		field := &mysql.Field{Type: test.typ, Flags: test.flags}
+		if v := textValue(field, []byte(test.in)); !reflect.DeepEqual(v, test.exp) {
+			t.Errorf("0x%x %q: exp: %#v res: %#v", test.typ, test.in, test.exp, v)
+		}
+	}
+}
+
+func TestTypedTextBadValue(t *testing.T) {
+	my := testConn(
+		_CLIENT_PROTOCOL_41, nil,
+		[]byte{2},
+		fieldPkt("d", MYSQL_TYPE_DATETIME, 63),
+		fieldPkt("i", MYSQL_TYPE_LONG, 63),
+		eofPkt,
+		append([]byte("\x132014-00-00 00:00:00"), 2, '1', '2'),
+		eofPkt,
+	)
+	my.SetTypedText(true)
+	rows, _, err := my.Query("select d, i from t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []mysql.Row{{[]byte("2014-00-00 00:00:00"), int32(12)}}
+	if !reflect.DeepEqual(rows, exp) {
+		t.Fatalf("exp: %#v res: %#v", exp, rows)
+	}
+	if my.unreaded_reply {
+		t.Fatal("unreaded reply after bad value")
+	}
+}
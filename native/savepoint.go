@@ -0,0 +1,98 @@
+package native
+
+import (
+	"github.com/ziutek/mymysql/mysql"
+	"strconv"
+	"strings"
+)
+
+func quoteSavepoint(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+// Returns top level transaction
+func (tr *Transaction) top() *Transaction {
+	if tr.root != nil {
+		return tr.root
+	}
+	return tr
+}
+
+// Returns index of savepoint name or -1 if there is no such savepoint
+func (tr *Transaction) findSavepoint(name string) int {
+	for ii := len(tr.savepoints) - 1; ii >= 0; ii-- {
+		if tr.savepoints[ii] == name {
+			return ii
+		}
+	}
+	return -1
+}
+
+func (tr *Transaction) setSavepoint(name string) error {
+	if _, err := tr.Start("SAVEPOINT " + quoteSavepoint(name)); err != nil {
+		return err
+	}
+	// Server replaces existing savepoint with the same name
+	if ii := tr.findSavepoint(name); ii >= 0 {
+		tr.savepoints = append(tr.savepoints[:ii], tr.savepoints[ii+1:]...)
+	}
+	tr.savepoints = append(tr.savepoints, name)
+	return nil
+}
+
+func (tr *Transaction) rollbackTo(name string) error {
+	ii := tr.findSavepoint(name)
+	if ii < 0 {
+		return mysql.ErrNoSavepoint
+	}
+	_, err := tr.Start("ROLLBACK TO SAVEPOINT " + quoteSavepoint(name))
+	if err == nil {
+		// Savepoints set after name are deleted by server
+		tr.savepoints = tr.savepoints[:ii+1]
+	}
+	return err
+}
+
+func (tr *Transaction) release(name string) error {
+	ii := tr.findSavepoint(name)
+	if ii < 0 {
+		return mysql.ErrNoSavepoint
+	}
+	_, err := tr.Start("RELEASE SAVEPOINT " + quoteSavepoint(name))
+	if err == nil {
+		// Savepoints set after name are released too
+		tr.savepoints = tr.savepoints[:ii]
+	}
+	return err
+}
+
+// Sets savepoint name. Existing savepoint with the same name is replaced.
+func (tr *Transaction) Savepoint(name string) error {
+	return tr.top().setSavepoint(name)
+}
+
+// Rolls back the transaction to savepoint name. Savepoints set later
+// (and nested transactions that use them) become invalid. Returns
+// mysql.ErrNoSavepoint if there is no such valid savepoint.
+func (tr *Transaction) RollbackTo(name string) error {
+	return tr.top().rollbackTo(name)
+}
+
+// Releases savepoint name and all savepoints set later. Returns
+// mysql.ErrNoSavepoint if there is no such valid savepoint.
+func (tr *Transaction) ReleaseSavepoint(name string) error {
+	return tr.top().release(name)
+}
+
+// Starts nested transaction. Nested transaction sets a savepoint: its Commit
+// releases the savepoint and its Rollback rolls back to it. Savepoints and
+// nested transactions are shared with the top level transaction.
+func (tr *Transaction) Begin() (mysql.Transaction, error) {
+	top := tr.top()
+	top.nested++
+	name := "_mymysql_sp" + strconv.Itoa(top.nested)
+	if err := top.setSavepoint(name); err != nil {
+		return nil, err
+	}
+	return &Transaction{Conn: tr.Conn, root: top, savepoint: name}, nil
+}
@@ -0,0 +1,141 @@
+package native
+
+import (
+	"bytes"
+	"github.com/ziutek/mymysql/mysql"
+	"testing"
+)
+
+func TestSavepoints(t *testing.T) {
+	var out bytes.Buffer
+	my := testConn(
+		0, &out,
+		okPkt, nil, okPkt, nil, okPkt, nil, okPkt, nil, okPkt, nil, okPkt,
+	)
+	tr, err := my.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tr.Savepoint("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err = tr.Savepoint("b`c"); err != nil {
+		t.Fatal(err)
+	}
+	if err = tr.RollbackTo("a"); err != nil {
+		t.Fatal(err)
+	}
+	// Savepoint b`c was deleted by rollback
+	if err = tr.RollbackTo("b`c"); err != mysql.ErrNoSavepoint {
+		t.Fatalf("RollbackTo returned %v", err)
+	}
+	if err = tr.ReleaseSavepoint("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err = tr.ReleaseSavepoint("a"); err != mysql.ErrNoSavepoint {
+		t.Fatalf("ReleaseSavepoint returned %v", err)
+	}
+	if err = tr.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if tr.IsValid() {
+		t.Fatal("transaction valid after commit")
+	}
+	checkCmds(t, &out,
+		"\x03START TRANSACTION",
+		"\x03SAVEPOINT `a`",
+		"\x03SAVEPOINT `b``c`",
+		"\x03ROLLBACK TO SAVEPOINT `a`",
+		"\x03RELEASE SAVEPOINT `a`",
+		"\x03COMMIT",
+	)
+}
+
+func TestNestedTransaction(t *testing.T) {
+	var out bytes.Buffer
+	my := testConn(
+		0, &out,
+		okPkt, nil, okPkt, nil, okPkt, nil, okPkt, nil, okPkt, nil, okPkt,
+		nil, okPkt, nil, okPkt, nil, okPkt,
+	)
+	tr, err := my.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n1, err := tr.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n2, err := n1.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = n2.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	n3, err := n1.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = n1.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	// Savepoint of n3 was released with savepoint of n1
+	if n1.IsValid() || n3.IsValid() || !tr.IsValid() {
+		t.Fatalf("bad validity: n1=%t n3=%t tr=%t",
+			n1.IsValid(), n3.IsValid(), tr.IsValid())
+	}
+	if err = n3.Commit(); err != mysql.ErrNoSavepoint {
+		t.Fatalf("Commit of invalid nested transaction returned %v", err)
+	}
+	n4, err := tr.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tr.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if n4.IsValid() {
+		t.Fatal("nested transaction valid after rollback")
+	}
+	checkCmds(t, &out,
+		"\x03START TRANSACTION",
+		"\x03SAVEPOINT `_mymysql_sp1`",
+		"\x03SAVEPOINT `_mymysql_sp2`",
+		"\x03RELEASE SAVEPOINT `_mymysql_sp2`",
+		"\x03SAVEPOINT `_mymysql_sp3`",
+		"\x03ROLLBACK TO SAVEPOINT `_mymysql_sp1`",
+		"\x03RELEASE SAVEPOINT `_mymysql_sp1`",
+		"\x03SAVEPOINT `_mymysql_sp4`",
+		"\x03ROLLBACK",
+	)
+}
+
+func TestBeginTx(t *testing.T) {
+	var out bytes.Buffer
+	my := testConn(0, &out, okPkt, nil, okPkt)
+	my.info.serv_ver = "8.0.36"
+	opts := mysql.TxOptions{
+		Isolation: mysql.ISOLATION_SERIALIZABLE,
+		ReadOnly:  true,
+	}
+	if _, err := my.BeginTx(opts); err != nil {
+		t.Fatal(err)
+	}
+	checkCmds(t, &out,
+		"\x03SET TRANSACTION ISOLATION LEVEL SERIALIZABLE",
+		"\x03START TRANSACTION READ ONLY",
+	)
+
+	my.info.serv_ver = "5.5.40"
+	if _, err := my.BeginTx(opts); err != mysql.ErrNotSupported {
+		t.Fatalf("BeginTx returned %v", err)
+	}
+	opts.ReadWrite = true
+	if _, err := my.BeginTx(opts); err != mysql.ErrTxOptions {
+		t.Fatalf("BeginTx returned %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("commands sent for invalid options: %q", out.Bytes())
+	}
+}
@@ -0,0 +1,178 @@
+package native
+
+import (
+	"strings"
+)
+
+// Server flavors
+const (
+	FLAVOR_MYSQL = iota
+	FLAVOR_MARIADB
+	FLAVOR_PERCONA
+	FLAVOR_TIDB
+)
+
+var flavorNames = []string{"MySQL", "MariaDB", "Percona", "TiDB"}
+
+// Information about the server obtained during handshake
+type ServerInfo struct {
+	ProtVer byte   // Protocol version
+	Version string // Version string as reported by server
+	Flavor  int    // FLAVOR_MYSQL, FLAVOR_MARIADB, FLAVOR_PERCONA, FLAVOR_TIDB
+	Caps    uint32 // Server capability flags
+	ConnId  uint32 // Connection (thread) id
+
+	// Version of the flavor (eg. 10.4.12 for MariaDB, 6.5.0 for TiDB)
+	Major, Minor, Patch int
+}
+
+// Parses server version string.
+func parseServerVersion(ver string) (flavor int, v [3]int) {
+	lver := strings.ToLower(ver)
+	switch {
+	case strings.Contains(lver, "mariadb"):
+		flavor = FLAVOR_MARIADB
+		// MariaDB 10 prefixes its version with 5.5.5- for compatibility
+		// with MySQL replication protocol
+		ver = strings.TrimPrefix(ver, "5.5.5-")
+	case strings.Contains(lver, "tidb"):
+		flavor = FLAVOR_TIDB
+		// eg. 5.7.25-TiDB-v6.5.0
+		if n := strings.Index(lver, "-tidb-"); n != -1 {
+			ver = strings.TrimPrefix(ver[n+6:], "v")
+		}
+	case strings.Contains(lver, "percona"):
+		flavor = FLAVOR_PERCONA
+	default:
+		// Percona Server reports version with numeric build suffix (eg.
+		// 8.0.28-19 or 5.7.36-39-log)
+		if n := strings.IndexByte(ver, '-'); n != -1 {
+			build := strings.SplitN(ver[n+1:], "-", 2)[0]
+			if build != "" && strings.Trim(build, "0123456789.") == "" {
+				flavor = FLAVOR_PERCONA
+			}
+		}
+	}
+	v = splitVersion(ver)
+	return
+}
+
+// Returns information about the server obtained during handshake. It is
+// valid after Connect.
+func (my *Conn) ServerInfo() *ServerInfo {
+	flavor, v := parseServerVersion(my.info.serv_ver)
+	return &ServerInfo{
+		ProtVer: my.info.prot_ver,
+		Version: my.info.serv_ver,
+		Flavor:  flavor,
+		Major:   v[0],
+		Minor:   v[1],
+		Patch:   v[2],
+		Caps:    my.info.caps,
+		ConnId:  my.info.thr_id,
+	}
+}
+
+// Returns name of server flavor.
+func (si *ServerInfo) FlavorName() string {
+	return flavorNames[si.Flavor]
+}
+
+// True if server version is at least major.minor.patch.
+func (si *ServerInfo) AtLeast(major, minor, patch int) bool {
+	if si.Major != major {
+		return si.Major > major
+	}
+	if si.Minor != minor {
+		return si.Minor > minor
+	}
+	return si.Patch >= patch
+}
+
+// True if server is MySQL compatible (MySQL or Percona Server) and its
+// version is at least major.minor.patch.
+func (si *ServerInfo) mysqlAtLeast(major, minor, patch int) bool {
+	return (si.Flavor == FLAVOR_MYSQL || si.Flavor == FLAVOR_PERCONA) &&
+		si.AtLeast(major, minor, patch)
+}
+
+// True if server supports JSON data type (MariaDB supports it as an alias
+// for LONGTEXT).
+func (si *ServerInfo) SupportsJSON() bool {
+	switch si.Flavor {
+	case FLAVOR_MARIADB:
+		return si.AtLeast(10, 2, 7)
+	case FLAVOR_TIDB:
+		return true
+	}
+	return si.AtLeast(5, 7, 8)
+}
+
+// True if server supports common table expressions (WITH queries).
+func (si *ServerInfo) SupportsCTE() bool {
+	switch si.Flavor {
+	case FLAVOR_MARIADB:
+		return si.AtLeast(10, 2, 1)
+	case FLAVOR_TIDB:
+		return si.AtLeast(5, 0, 0)
+	}
+	return si.AtLeast(8, 0, 1)
+}
+
+// True if server supports window functions.
+func (si *ServerInfo) SupportsWindowFunctions() bool {
+	switch si.Flavor {
+	case FLAVOR_MARIADB:
+		return si.AtLeast(10, 2, 0)
+	case FLAVOR_TIDB:
+		return si.AtLeast(3, 0, 0)
+	}
+	return si.AtLeast(8, 0, 2)
+}
+
+// True if server supports caching_sha2_password authentication.
+func (si *ServerInfo) SupportsCachingSHA2() bool {
+	if si.Flavor == FLAVOR_TIDB {
+		return si.AtLeast(5, 2, 0)
+	}
+	return si.mysqlAtLeast(8, 0, 4)
+}
+
+// True if server supports COM_RESET_CONNECTION.
+func (si *ServerInfo) SupportsResetConnection() bool {
+	switch si.Flavor {
+	case FLAVOR_MARIADB:
+		return si.AtLeast(10, 2, 4)
+	case FLAVOR_TIDB:
+		return si.AtLeast(5, 4, 0)
+	}
+	return si.AtLeast(5, 7, 3)
+}
+
+// True if server supports COM_BINLOG_DUMP_GTID (MySQL 5.6 GTIDs).
+func (si *ServerInfo) SupportsBinlogDumpGtid() bool {
+	return si.mysqlAtLeast(5, 6, 0)
+}
+
+// True if server supports READ ONLY and READ WRITE transaction access modes.
+func (si *ServerInfo) SupportsTxAccessMode() bool {
+	if si.Flavor == FLAVOR_MARIADB {
+		return si.AtLeast(10, 0, 0)
+	}
+	return si.AtLeast(5, 6, 5)
+}
+
+// True if server supports COM_STMT_BULK_EXECUTE.
+func (si *ServerInfo) SupportsBulkExecute() bool {
+	return si.Flavor == FLAVOR_MARIADB && si.AtLeast(10, 2, 0)
+}
+
+// True if server sends session state changes in OK packets.
+func (si *ServerInfo) SupportsSessionTrack() bool {
+	return si.Caps&_CLIENT_SESSION_TRACK != 0
+}
+
+// True if server can replace EOF packets with OK packets.
+func (si *ServerInfo) SupportsDeprecateEOF() bool {
+	return si.Caps&_CLIENT_DEPRECATE_EOF != 0
+}
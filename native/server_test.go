@@ -0,0 +1,67 @@
+package native
+
+import (
+	"testing"
+)
+
+func TestParseServerVersion(t *testing.T) {
+	tests := []struct {
+		ver    string
+		flavor int
+		v      [3]int
+	}{
+		{"5.1.73-log", FLAVOR_MYSQL, [3]int{5, 1, 73}},
+		{"8.0.36", FLAVOR_MYSQL, [3]int{8, 0, 36}},
+		{"8.0.28-0ubuntu0.20.04.3", FLAVOR_MYSQL, [3]int{8, 0, 28}},
+		{"5.5.5-10.4.12-MariaDB-1:10.4.12+maria~bionic", FLAVOR_MARIADB,
+			[3]int{10, 4, 12}},
+		{"10.11.6-MariaDB", FLAVOR_MARIADB, [3]int{10, 11, 6}},
+		{"8.0.28-19", FLAVOR_PERCONA, [3]int{8, 0, 28}},
+		{"5.7.36-39-log", FLAVOR_PERCONA, [3]int{5, 7, 36}},
+		{"5.6.51-Percona", FLAVOR_PERCONA, [3]int{5, 6, 51}},
+		{"5.7.25-TiDB-v6.5.0", FLAVOR_TIDB, [3]int{6, 5, 0}},
+	}
+	for _, tt := range tests {
+		flavor, v := parseServerVersion(tt.ver)
+		if flavor != tt.flavor || v != tt.v {
+			t.Errorf("%s: flavor=%d version=%v exp: %d %v",
+				tt.ver, flavor, v, tt.flavor, tt.v)
+		}
+	}
+}
+
+func TestServerInfo(t *testing.T) {
+	my := testConn(0, nil)
+	my.info = serverInfo{
+		prot_ver: 10,
+		serv_ver: "8.0.36",
+		thr_id:   42,
+		caps:     _CLIENT_PROTOCOL_41 | _CLIENT_DEPRECATE_EOF,
+	}
+	si := my.ServerInfo()
+	if si.ProtVer != 10 || si.ConnId != 42 || si.FlavorName() != "MySQL" ||
+		!si.SupportsDeprecateEOF() || si.SupportsSessionTrack() {
+		t.Fatalf("bad server info: %+v", si)
+	}
+	if !si.SupportsJSON() || !si.SupportsCTE() || !si.SupportsCachingSHA2() ||
+		!si.SupportsBinlogDumpGtid() || si.SupportsBulkExecute() {
+		t.Fatalf("bad features of %s", si.Version)
+	}
+	if !si.AtLeast(8, 0, 36) || si.AtLeast(8, 0, 37) || !si.AtLeast(5, 7, 99) ||
+		si.AtLeast(8, 1, 0) {
+		t.Fatal("bad AtLeast result")
+	}
+
+	my.info.serv_ver = "5.5.5-10.3.39-MariaDB"
+	si = my.ServerInfo()
+	if !si.SupportsJSON() || !si.SupportsCTE() || si.SupportsCachingSHA2() ||
+		si.SupportsBinlogDumpGtid() || !si.SupportsBulkExecute() {
+		t.Fatalf("bad features of %s", si.Version)
+	}
+
+	my.info.serv_ver = "5.6.10"
+	si = my.ServerInfo()
+	if si.SupportsJSON() || si.SupportsCTE() || si.SupportsResetConnection() {
+		t.Fatalf("bad features of %s", si.Version)
+	}
+}
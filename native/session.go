@@ -0,0 +1,84 @@
+package native
+
+import (
+	"bytes"
+	"github.com/ziutek/mymysql/mysql"
+	"log"
+)
+
+// Reads the rest of OK packet: info message and session state changes (if
+// CLIENT_SESSION_TRACK is used). Reported changes are added to my.session.
+func (my *Conn) getOkInfo(pr *pktReader, status uint16) (info []byte, ss *mysql.SessionState) {
+	if my.caps&_CLIENT_SESSION_TRACK == 0 {
+		return pr.readAll(), nil
+	}
+	if !pr.eof() {
+		info = readBin(pr)
+	}
+	if status&_SERVER_SESSION_STATE_CHANGED == 0 || pr.eof() {
+		return
+	}
+	ss = parseSessionState(readBin(pr))
+	if my.session == nil {
+		my.session = new(mysql.SessionState)
+	}
+	my.session.Merge(ss)
+	if my.Debug {
+		log.Printf(tab8s+"SessionState=%+v", *ss)
+	}
+	return
+}
+
+// Parses session state information from OK packet
+func parseSessionState(buf []byte) *mysql.SessionState {
+	ss := new(mysql.SessionState)
+	rd := bytes.NewReader(buf)
+	for rd.Len() > 0 {
+		typ := readByte(rd)
+		data := bytes.NewReader(readBin(rd))
+		switch typ {
+		case _SESSION_TRACK_SYSTEM_VARIABLES:
+			if ss.Variables == nil {
+				ss.Variables = make(map[string]string)
+			}
+			name := readStr(data)
+			ss.Variables[name] = readStr(data)
+		case _SESSION_TRACK_SCHEMA:
+			ss.Schema = readStr(data)
+		case _SESSION_TRACK_STATE_CHANGE:
+			// Always "1"
+		case _SESSION_TRACK_GTIDS:
+			read(data, 1) // Encoding specification
+			ss.GTIDs = readStr(data)
+		case _SESSION_TRACK_TRANSACTION_CHARACTERISTICS:
+			ss.TxCharacteristics = readStr(data)
+		case _SESSION_TRACK_TRANSACTION_STATE:
+			ss.TxState = readStr(data)
+		default:
+			// Unknown type - ignore it
+			continue
+		}
+		ss.Changes |= 1 << typ
+	}
+	return ss
+}
+
+// Returns session state changes reported by server in OK packet of this
+// result or nil if there is no such changes (see session_track_* system
+// variables).
+func (res *Result) SessionState() *mysql.SessionState {
+	return res.session
+}
+
+// Returns session state changes (USE, SET, transaction state...) reported by
+// server since connect or last ClearSessionState call. Changes made by
+// registered initialisation commands aren't included. Returns nil if there
+// is no reported changes.
+func (my *Conn) SessionState() *mysql.SessionState {
+	return my.session
+}
+
+// Forgets session state changes returned by SessionState.
+func (my *Conn) ClearSessionState() {
+	my.session = nil
+}
@@ -0,0 +1,171 @@
+package native
+
+import (
+	"container/list"
+	"github.com/ziutek/mymysql/mysql"
+)
+
+// LRU cache of prepared statements used by PrepareCached
+type stmtCache struct {
+	size int                      // Maximum number of statements
+	lru  *list.List               // Statements, most recently used first
+	sqls map[string]*list.Element // Maps SQL text to element of lru
+}
+
+// Returns statement prepared for sql (and marks it as most recently used) or
+// nil if there is no such statement in the cache.
+func (c *stmtCache) get(sql string) *Stmt {
+	el := c.sqls[sql]
+	if el == nil {
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	return el.Value.(*Stmt)
+}
+
+func (c *stmtCache) add(stmt *Stmt) {
+	if c.lru == nil {
+		c.lru = list.New()
+		c.sqls = make(map[string]*list.Element)
+	}
+	c.sqls[stmt.sql] = c.lru.PushFront(stmt)
+}
+
+func (c *stmtCache) has(stmt *Stmt) bool {
+	el := c.sqls[stmt.sql]
+	return el != nil && el.Value == stmt
+}
+
+func (c *stmtCache) remove(stmt *Stmt) {
+	if c.has(stmt) {
+		c.lru.Remove(c.sqls[stmt.sql])
+		delete(c.sqls, stmt.sql)
+	}
+}
+
+func (c *stmtCache) len() int {
+	if c.lru == nil {
+		return 0
+	}
+	return c.lru.Len()
+}
+
+// Returns the least recently used statement or nil if the cache is empty
+func (c *stmtCache) oldest() *Stmt {
+	if c.len() == 0 {
+		return nil
+	}
+	return c.lru.Back().Value.(*Stmt)
+}
+
+// If size > 0 sets maximum number of statements in the cache used by
+// PrepareCached (default 32). Returns old size. Excess statements are deleted
+// by next PrepareCached call.
+func (my *Conn) SetStmtCacheSize(size int) int {
+	old_size := my.stmt_cache.size
+	if size > 0 {
+		my.stmt_cache.size = size
+	}
+	return old_size
+}
+
+// Deletes least recently used statement from the cache and the server.
+// Returns false if the cache is empty.
+func (my *Conn) evictStmt() bool {
+	stmt := my.stmt_cache.oldest()
+	if stmt == nil {
+		return false
+	}
+	if err := stmt.Delete(); err != nil {
+		panic(err)
+	}
+	return true
+}
+
+func (my *Conn) prepareCached(sql string) (stmt *Stmt, err error) {
+	defer catchError(&err)
+
+	if my.net_conn == nil {
+		return nil, mysql.ErrNotConn
+	}
+	if my.unreaded_reply {
+		return nil, mysql.ErrUnreadedReply
+	}
+
+	if stmt = my.stmt_cache.get(sql); stmt != nil {
+		return
+	}
+	for my.stmt_cache.len() >= my.stmt_cache.size {
+		if !my.evictStmt() {
+			break
+		}
+	}
+	for {
+		stmt, err = my.prepare(sql)
+		if e, ok := err.(*mysql.Error); ok &&
+			e.Code == mysql.ER_MAX_PREPARED_STMT_COUNT_REACHED &&
+			my.evictStmt() {
+			// Server limit of prepared statements (max_prepared_stmt_count)
+			// is reached. One statement was deleted, so try again.
+			continue
+		}
+		break
+	}
+	if err != nil {
+		return
+	}
+	my.stmt_map[stmt.id] = stmt
+	stmt.sql = sql
+	my.stmt_cache.add(stmt)
+	return
+}
+
+// Returns prepared statement for sql from the cache of prepared statements.
+// If there is no such statement it is prepared and added to the cache. When
+// the cache is full or the server limit of prepared statements is reached,
+// the least recently used statements are deleted. Returned statement is owned
+// by the cache: don't delete it and don't keep it for later use, because it
+// can be deleted by subsequent PrepareCached calls, Use, Reconnect and
+// ResetSession.
+func (my *Conn) PrepareCached(sql string) (mysql.Stmt, error) {
+	stmt, err := my.prepareCached(sql)
+	if err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// Executes statement from the cache of prepared statements (see
+// PrepareCached) with given parameters. Returns all rows.
+func (my *Conn) ExecCached(sql string, params ...interface{}) ([]mysql.Row, mysql.Result, error) {
+	stmt, err := my.prepareCached(sql)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stmt.Exec(params...)
+}
+
+// Deletes all cached statements from the cache and the server.
+func (my *Conn) deleteCachedStmts() {
+	for my.evictStmt() {
+	}
+}
+
+// Forgets cached statements discarded by the server (after reconnect or
+// session reset).
+func (my *Conn) dropCachedStmts() {
+	for stmt := my.stmt_cache.oldest(); stmt != nil; stmt = my.stmt_cache.oldest() {
+		my.stmt_cache.remove(stmt)
+		delete(my.stmt_map, stmt.id)
+		*stmt = Stmt{}
+	}
+}
+
+// Deletes stmt if it is cached and the server reports that it should be
+// reprepared (next PrepareCached call will prepare it again).
+func (stmt *Stmt) uncacheStale(err *error) {
+	if e, ok := (*err).(*mysql.Error); ok && e.Code == mysql.ER_NEED_REPREPARE &&
+		stmt.my != nil && stmt.my.stmt_cache.has(stmt) {
+		stmt.Delete()
+	}
+}
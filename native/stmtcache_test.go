@@ -0,0 +1,107 @@
+package native
+
+import (
+	"bytes"
+	"github.com/ziutek/mymysql/mysql"
+	"testing"
+)
+
+func prepOkPkt(id byte) []byte {
+	return []byte{0, id, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+}
+
+func checkCmds(t *testing.T, out *bytes.Buffer, exp ...string) {
+	pkts := cmdPkts(out)
+	if len(pkts) != len(exp) {
+		t.Fatalf("commands: %q exp: %q", pkts, exp)
+	}
+	for ii, e := range exp {
+		if pkts[ii] != e {
+			t.Fatalf("command %d: %q exp: %q", ii, pkts[ii], e)
+		}
+	}
+	out.Reset()
+}
+
+func TestStmtCache(t *testing.T) {
+	var out bytes.Buffer
+	my := testConn(
+		0, &out,
+		prepOkPkt(1), nil, prepOkPkt(2), nil,
+		[]byte("\xff\xb5\x05#42000Can't create more than max_prepared_stmt_count"),
+		nil, prepOkPkt(3), nil, okPkt,
+	)
+	my.stmt_cache.size = 3
+
+	a, err := my.PrepareCached("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := my.PrepareCached("A"); s != a {
+		t.Fatal("statement A isn't cached")
+	}
+	if _, err = my.PrepareCached("B"); err != nil {
+		t.Fatal(err)
+	}
+	checkCmds(t, &out, "\x16A", "\x16B")
+
+	// Statement limit reached: least recently used A is deleted
+	c, err := my.PrepareCached("C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCmds(t, &out, "\x16C", "\x19\x01\x00\x00\x00", "\x16C")
+	if c.(*Stmt).id != 3 || len(my.stmt_map) != 2 || my.stmt_cache.len() != 2 {
+		t.Fatalf("bad cache state: %+v %v", c, my.stmt_map)
+	}
+
+	// Use deletes all cached statements
+	if err = my.Use("test"); err != nil {
+		t.Fatal(err)
+	}
+	checkCmds(
+		t, &out,
+		"\x02test", "\x19\x02\x00\x00\x00", "\x19\x03\x00\x00\x00",
+	)
+	if len(my.stmt_map) != 0 || my.stmt_cache.len() != 0 {
+		t.Fatalf("statements not deleted: %v", my.stmt_map)
+	}
+}
+
+func TestStmtCacheEvict(t *testing.T) {
+	var out bytes.Buffer
+	my := testConn(
+		0, &out,
+		prepOkPkt(1), nil, prepOkPkt(2), nil, prepOkPkt(3), nil,
+		needReprepare, nil, prepOkPkt(4), nil, needReprepare,
+	)
+	my.stmt_cache.size = 2
+	for _, sql := range []string{"A", "B", "A", "C"} {
+		if _, err := my.PrepareCached(sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// B is the least recently used
+	checkCmds(t, &out, "\x16A", "\x16B", "\x19\x02\x00\x00\x00", "\x16C")
+
+	// Reprepared statement fails again
+	c := my.stmt_cache.get("C")
+	_, err := c.Run()
+	if e, ok := err.(*mysql.Error); !ok || e.Code != mysql.ER_NEED_REPREPARE {
+		t.Fatalf("Run returned %v", err)
+	}
+	if my.stmt_cache.get("C") != nil || len(my.stmt_map) != 1 {
+		t.Fatal("stale statement is still cached")
+	}
+	pkts := cmdPkts(&out)
+	if len(pkts) != 5 || pkts[4] != "\x19\x04\x00\x00\x00" {
+		t.Fatalf("stale statement not deleted: %q", pkts)
+	}
+	out.Reset()
+
+	// Reconnect/ResetSession forget cached statements
+	my.dropCachedStmts()
+	if len(my.stmt_map) != 0 || my.stmt_cache.len() != 0 || out.Len() != 0 {
+		t.Fatalf("bad cache state: %v", my.stmt_map)
+	}
+}
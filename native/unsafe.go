@@ -12,12 +12,23 @@ type paramValue struct {
 	addr   unsafe.Pointer
 	raw    bool
 	length int // >=0 - length of value, <0 - unknown length
+
+	marshal func() []byte // Encodes JSON, geometry or string value (nil if NULL)
+	encoded []byte        // Value encoded by Len for writeValue
 }
 
 func (pv *paramValue) SetAddr(addr uintptr) {
 	pv.addr = unsafe.Pointer(addr)
 }
 
+// Returns function that converts string parameter to character set of the
+// connection (see encodeString).
+func (my *Conn) stringEncoder(val *paramValue) func() []byte {
+	return func() []byte {
+		return my.encodeString(**(**string)(val.addr))
+	}
+}
+
 func (val *paramValue) Len() int {
 	if val.addr == nil {
 		// Invalid Value was binded
@@ -33,6 +44,12 @@ func (val *paramValue) Len() int {
 	if val.length >= 0 {
 		return val.length
 	}
+	if val.marshal != nil {
+		if val.encoded = val.marshal(); val.encoded == nil {
+			return 0
+		}
+		return lenBin(val.encoded)
+	}
 
 	switch val.typ {
 	case MYSQL_TYPE_STRING:
@@ -66,6 +83,17 @@ func writeValue(wr io.Writer, val *paramValue) {
 		return
 	}
 
+	if val.marshal != nil {
+		buf := val.encoded
+		if buf == nil {
+			buf = val.marshal()
+		}
+		val.encoded = nil
+		if buf != nil {
+			writeBin(wr, buf)
+		}
+		return
+	}
 	if val.raw || val.typ == MYSQL_TYPE_VAR_STRING ||
 		val.typ == MYSQL_TYPE_BLOB {
 		writeBin(wr, *(*[]byte)(ptr))
@@ -45,9 +45,16 @@ type Stmt struct {
 	conn *Conn
 }
 
+type Pipeline struct {
+	mysql.Pipeline
+	conn *Conn
+}
+
 type Transaction struct {
 	*Conn
-	conn *Conn
+	conn   *Conn
+	tx     mysql.Transaction // Transaction of underlying connection
+	nested bool
 }
 
 func New(proto, laddr, raddr, user, passwd string, db ...string) mysql.Conn {
@@ -120,6 +127,19 @@ func (c *Conn) Use(dbname string) error {
 	return c.Conn.Use(dbname)
 }
 
+func (c *Conn) ChangeCharset(name string) error {
+	//log.Println("ChangeCharset")
+	c.lock()
+	defer c.unlock()
+	return c.Conn.ChangeCharset(name)
+}
+
+func (c *Conn) ResetSession() error {
+	c.lock()
+	defer c.unlock()
+	return c.Conn.ResetSession()
+}
+
 func (c *Conn) Start(sql string, params ...interface{}) (mysql.Result, error) {
 	//log.Println("Start")
 	c.lock()
@@ -191,6 +211,57 @@ func (c *Conn) Prepare(sql string) (mysql.Stmt, error) {
 	return &Stmt{Stmt: stmt, conn: c}, nil
 }
 
+// Other goroutines can delete returned statement (see
+// native.Conn.PrepareCached) so use ExecCached instead, if the connection is
+// shared.
+func (c *Conn) PrepareCached(sql string) (mysql.Stmt, error) {
+	c.lock()
+	defer c.unlock()
+	stmt, err := c.Conn.PrepareCached(sql)
+	if err != nil {
+		return nil, err
+	}
+	return &Stmt{Stmt: stmt, conn: c}, nil
+}
+
+func (c *Conn) Pipeline() mysql.Pipeline {
+	return &Pipeline{Pipeline: c.Conn.Pipeline(), conn: c}
+}
+
+func (p *Pipeline) Query(sql string, params ...interface{}) error {
+	p.conn.lock()
+	defer p.conn.unlock()
+	return p.Pipeline.Query(sql, params...)
+}
+
+func (p *Pipeline) Exec(stmt mysql.Stmt, params ...interface{}) error {
+	if s, ok := stmt.(*Stmt); ok {
+		stmt = s.Stmt
+	}
+	p.conn.lock()
+	defer p.conn.unlock()
+	return p.Pipeline.Exec(stmt, params...)
+}
+
+// Connection is locked until all responses are read.
+func (p *Pipeline) Run() ([]mysql.PipelineResult, error) {
+	p.conn.lock()
+	defer p.conn.unlock()
+	return p.Pipeline.Run()
+}
+
+func (c *Conn) ExecCached(sql string, params ...interface{}) ([]mysql.Row, mysql.Result, error) {
+	c.lock()
+	defer c.unlock()
+	return c.Conn.ExecCached(sql, params...)
+}
+
+func (c *Conn) CallProc(name string, params ...interface{}) ([][]mysql.Row, mysql.Result, error) {
+	c.lock()
+	defer c.unlock()
+	return c.Conn.CallProc(name, params...)
+}
+
 func (stmt *Stmt) Run(params ...interface{}) (mysql.Result, error) {
 	//log.Println("Run")
 	stmt.conn.lock()
@@ -227,6 +298,12 @@ func (stmt *Stmt) SendLongData(pnum int, data interface{}, pkt_size int) error {
 	return stmt.Stmt.SendLongData(pnum, data, pkt_size)
 }
 
+func (stmt *Stmt) ExecBatch(rows [][]interface{}) ([]mysql.BatchResult, error) {
+	stmt.conn.lock()
+	defer stmt.conn.unlock()
+	return stmt.Stmt.ExecBatch(rows)
+}
+
 // See mysql.Query
 func (c *Conn) Query(sql string, params ...interface{}) ([]mysql.Row, mysql.Result, error) {
 	return mysql.Query(c, sql, params...)
@@ -283,22 +360,35 @@ func (res *Result) GetRows() ([]mysql.Row, error) {
 
 func (c *Conn) Begin() (mysql.Transaction, error) {
 	//log.Println("Begin")
+	return c.BeginTx(mysql.TxOptions{})
+}
+
+func (c *Conn) BeginTx(opts mysql.TxOptions) (mysql.Transaction, error) {
 	c.lock()
-	tr := Transaction{
-		&Conn{Conn: c.Conn, mutex: new(sync.Mutex)},
-		c,
-	}
-	_, err := c.Conn.Start("START TRANSACTION")
+	tx, err := c.Conn.BeginTx(opts)
 	if err != nil {
 		c.unlock()
 		return nil, err
 	}
+	tr := Transaction{
+		&Conn{Conn: c.Conn, mutex: new(sync.Mutex)},
+		c,
+		tx,
+		false,
+	}
 	return &tr, nil
 }
 
-func (tr *Transaction) end(cr string) error {
+func (tr *Transaction) end(end func() error) error {
 	tr.lock()
-	_, err := tr.conn.Conn.Start(cr)
+	err := end()
+	if tr.nested {
+		tr.unlock()
+		// Invalidate this transaction
+		tr.Conn = nil
+		tr.conn = nil
+		return err
+	}
 	tr.conn.unlock()
 	// Invalidate this transaction
 	m := tr.Conn.mutex
@@ -310,16 +400,50 @@ func (tr *Transaction) end(cr string) error {
 
 func (tr *Transaction) Commit() error {
 	//log.Println("Commit")
-	return tr.end("COMMIT")
+	return tr.end(tr.tx.Commit)
 }
 
 func (tr *Transaction) Rollback() error {
 	//log.Println("Rollback")
-	return tr.end("ROLLBACK")
+	return tr.end(tr.tx.Rollback)
+}
+
+// Starts nested transaction (see native.Transaction.Begin).
+func (tr *Transaction) Begin() (mysql.Transaction, error) {
+	tr.lock()
+	defer tr.unlock()
+	tx, err := tr.tx.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{tr.Conn, tr.conn, tx, true}, nil
+}
+
+func (tr *Transaction) Savepoint(name string) error {
+	tr.lock()
+	defer tr.unlock()
+	return tr.tx.Savepoint(name)
+}
+
+func (tr *Transaction) RollbackTo(name string) error {
+	tr.lock()
+	defer tr.unlock()
+	return tr.tx.RollbackTo(name)
+}
+
+func (tr *Transaction) ReleaseSavepoint(name string) error {
+	tr.lock()
+	defer tr.unlock()
+	return tr.tx.ReleaseSavepoint(name)
 }
 
 func (tr *Transaction) IsValid() bool {
-	return tr.Conn != nil
+	if tr.Conn == nil {
+		return false
+	}
+	tr.lock()
+	defer tr.unlock()
+	return tr.tx.IsValid()
 }
 
 func (tr *Transaction) Do(st mysql.Stmt) mysql.Stmt {